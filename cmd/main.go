@@ -1,36 +1,42 @@
 package main
 
 import (
-	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"pvs/internal/app"
-	"pvs/internal/config"
-	"syscall"
+	"strings"
 )
 
-func main() {
-	_ = config.Load()
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+const usage = `usage:
+  pvz [serve] [flags]                  run the service (default)
+  pvz migrate up|down|status|version [flags]
+  pvz migrate create [-dir migrations] NAME
+`
 
-	a, err := app.NewApp(ctx)
-	if err != nil {
-		log.Fatalf("failed to start app: %v", err)
+func main() {
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	go func() {
-		if err := a.Run(); err != nil {
-			log.Fatalf("server error: %v", err)
-		}
-	}()
-
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
-	<-stop
+	var err error
+	switch command {
+	case "serve":
+		err = serve(args)
+	case "migrate":
+		err = runMigrate(args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
 
-	if err := a.Stop(ctx); err != nil {
-		log.Fatalf("failed to shutdown: %v", err)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("❌ %s: %v", command, err)
 	}
 }
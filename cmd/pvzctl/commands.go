@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"pvs/internal/domain"
+)
+
+// stdin is where createUser reads the password from. There is deliberately
+// no flag for it: arguments end up in the process list and shell history.
+var stdin io.Reader = os.Stdin
+
+func createUser(ctx context.Context, s *services, out *printer, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	email := fs.String("email", "", "email of the new user")
+	role := fs.String("role", "", "employee or moderator")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+	if err != nil && password == "" {
+		return errors.New("password is required: write it to stdin")
+	}
+
+	user, err := s.users.CreateUser(ctx, *email, password, *role, operator)
+	if err != nil {
+		return err
+	}
+	return render(out, []userRow{newUserRow(*user)})
+}
+
+func listUsers(ctx context.Context, s *services, out *printer, _ []string) error {
+	users, err := s.users.ListUsers(ctx, operator)
+	if err != nil {
+		return err
+	}
+	rows := make([]userRow, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, newUserRow(u))
+	}
+	return render(out, rows)
+}
+
+func setRole(ctx context.Context, s *services, out *printer, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: user set-role USER_ID ROLE")
+	}
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid user id: %w", err)
+	}
+
+	user, err := s.users.SetRole(ctx, id, args[1], operator)
+	if err != nil {
+		return err
+	}
+	return render(out, []userRow{newUserRow(*user)})
+}
+
+func createPVZ(ctx context.Context, s *services, out *printer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: pvz create CITY")
+	}
+
+	pvz, err := s.pvz.CreatePVZ(ctx, args[0], operator)
+	if err != nil {
+		return err
+	}
+	return render(out, []pvzRow{{ID: pvz.ID, City: pvz.City, RegistrationDate: pvz.RegistrationDate}})
+}
+
+func listPVZ(ctx context.Context, s *services, out *printer, args []string) error {
+	fs := flag.NewFlagSet("pvz list", flag.ContinueOnError)
+	page := fs.Int("page", 1, "page number")
+	limit := fs.Int("limit", 30, "page size")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := s.pvz.ListPVZWithFilter(ctx, domain.PVZFilter{Page: *page, Limit: *limit})
+	if err != nil {
+		return err
+	}
+	rows := make([]pvzRow, 0, len(result.Items))
+	for _, item := range result.Items {
+		rows = append(rows, pvzRow{
+			ID:               item.PVZ.ID,
+			City:             item.PVZ.City,
+			RegistrationDate: item.PVZ.RegistrationDate,
+			Receptions:       len(item.Receptions),
+		})
+	}
+	if err := render(out, rows); err != nil {
+		return err
+	}
+	if result.HasMore && out.format == formatTable {
+		fmt.Fprintf(out.hints, "%d of %d shown, next page: -page %d\n", len(rows), result.Total, *page+1)
+	}
+	return nil
+}
+
+func closeReception(ctx context.Context, s *services, out *printer, args []string) error {
+	id, err := receptionID(args, "reception close")
+	if err != nil {
+		return err
+	}
+
+	reception, err := s.receptions.ForceCloseReception(ctx, id, operator)
+	if err != nil {
+		return err
+	}
+	return render(out, []receptionRow{newReceptionRow(*reception)})
+}
+
+func listProducts(ctx context.Context, s *services, out *printer, args []string) error {
+	id, err := receptionID(args, "reception products")
+	if err != nil {
+		return err
+	}
+
+	reception, err := s.receptions.GetReception(ctx, id, operator)
+	if err != nil {
+		return err
+	}
+	rows := make([]productRow, 0, len(reception.Products))
+	for _, p := range reception.Products {
+		rows = append(rows, productRow{ID: p.ID, Type: p.Type, DateTime: p.DateTime})
+	}
+	return render(out, rows)
+}
+
+func receptionID(args []string, command string) (uuid.UUID, error) {
+	if len(args) != 1 {
+		return uuid.Nil, fmt.Errorf("usage: %s RECEPTION_ID", command)
+	}
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid reception id: %w", err)
+	}
+	return id, nil
+}
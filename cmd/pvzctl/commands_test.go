@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"pvs/internal/domain"
+)
+
+type mockUserService struct {
+	mock.Mock
+}
+
+func (m *mockUserService) CreateUser(ctx context.Context, email, password, role string, principal domain.Principal) (*domain.User, error) {
+	args := m.Called(ctx, email, password, role, principal)
+	if u := args.Get(0); u != nil {
+		return u.(*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserService) ListUsers(ctx context.Context, principal domain.Principal) ([]domain.User, error) {
+	args := m.Called(ctx, principal)
+	if u := args.Get(0); u != nil {
+		return u.([]domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserService) SetRole(ctx context.Context, id uuid.UUID, role string, principal domain.Principal) (*domain.User, error) {
+	args := m.Called(ctx, id, role, principal)
+	if u := args.Get(0); u != nil {
+		return u.(*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockPVZService struct {
+	mock.Mock
+}
+
+func (m *mockPVZService) CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error) {
+	args := m.Called(ctx, city, principal)
+	if p := args.Get(0); p != nil {
+		return p.(*domain.PVZ), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPVZService) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error) {
+	args := m.Called(ctx, filter)
+	if p := args.Get(0); p != nil {
+		return p.(*domain.PVZPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockReceptionService struct {
+	mock.Mock
+}
+
+func (m *mockReceptionService) ForceCloseReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	args := m.Called(ctx, id, principal)
+	if r := args.Get(0); r != nil {
+		return r.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionService) GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error) {
+	args := m.Called(ctx, id, principal)
+	if r := args.Get(0); r != nil {
+		return r.(*domain.ReceptionWithProducts), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type fixture struct {
+	users      *mockUserService
+	pvz        *mockPVZService
+	receptions *mockReceptionService
+	out        bytes.Buffer
+	hints      bytes.Buffer
+}
+
+func newFixture() *fixture {
+	return &fixture{
+		users:      new(mockUserService),
+		pvz:        new(mockPVZService),
+		receptions: new(mockReceptionService),
+	}
+}
+
+// exec dispatches args the way main does after the database is connected.
+func (f *fixture) exec(t *testing.T, format string, args ...string) error {
+	t.Helper()
+	cmd, err := lookup(args)
+	if err != nil {
+		return err
+	}
+	s := &services{users: f.users, pvz: f.pvz, receptions: f.receptions}
+	return cmd(context.Background(), s, newPrinter(&f.out, &f.hints, format), args[2:])
+}
+
+func setStdin(t *testing.T, input string) {
+	old := stdin
+	stdin = strings.NewReader(input)
+	t.Cleanup(func() { stdin = old })
+}
+
+func TestUserCreate_PasswordFromStdin(t *testing.T) {
+	f := newFixture()
+	setStdin(t, "s3cret-pass\n")
+
+	id := uuid.New()
+	f.users.On("CreateUser", mock.Anything, "mod@example.com", "s3cret-pass", "moderator", operator).
+		Return(&domain.User{ID: id, Email: "mod@example.com", Role: "moderator"}, nil)
+
+	err := f.exec(t, formatTable, "user", "create", "-email", "mod@example.com", "-role", "moderator")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ID                                    EMAIL            ROLE\n"+
+		id.String()+"  mod@example.com  moderator\n", f.out.String())
+	f.users.AssertExpectations(t)
+}
+
+func TestUserCreate_EmptyStdin(t *testing.T) {
+	f := newFixture()
+	setStdin(t, "")
+
+	err := f.exec(t, formatTable, "user", "create", "-email", "mod@example.com", "-role", "moderator")
+
+	assert.EqualError(t, err, "password is required: write it to stdin")
+	f.users.AssertNotCalled(t, "CreateUser")
+}
+
+func TestUserCreate_NoPasswordFlag(t *testing.T) {
+	f := newFixture()
+	setStdin(t, "")
+
+	err := f.exec(t, formatTable, "user", "create", "-email", "mod@example.com", "-role", "moderator", "-password", "s3cret-pass")
+
+	assert.ErrorContains(t, err, "flag provided but not defined: -password")
+	f.users.AssertNotCalled(t, "CreateUser")
+}
+
+func TestUserList_JSON(t *testing.T) {
+	f := newFixture()
+	id := uuid.MustParse("6f1c2f9e-3f5a-4f4e-9c1d-2b8d9c6a7e10")
+	f.users.On("ListUsers", mock.Anything, operator).
+		Return([]domain.User{{ID: id, Email: "a@example.com", Role: "employee"}}, nil)
+
+	err := f.exec(t, formatJSON, "user", "list")
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"id":"6f1c2f9e-3f5a-4f4e-9c1d-2b8d9c6a7e10","email":"a@example.com","role":"employee"}]`, f.out.String())
+}
+
+func TestUserList_EmptyJSONIsArray(t *testing.T) {
+	f := newFixture()
+	f.users.On("ListUsers", mock.Anything, operator).Return([]domain.User{}, nil)
+
+	assert.NoError(t, f.exec(t, formatJSON, "user", "list"))
+	assert.Equal(t, "[]\n", f.out.String())
+}
+
+func TestUserSetRole(t *testing.T) {
+	f := newFixture()
+	id := uuid.New()
+	f.users.On("SetRole", mock.Anything, id, "moderator", operator).
+		Return(&domain.User{ID: id, Email: "a@example.com", Role: "moderator"}, nil)
+
+	assert.NoError(t, f.exec(t, formatTable, "user", "set-role", id.String(), "moderator"))
+	assert.Contains(t, f.out.String(), "moderator")
+
+	assert.EqualError(t, f.exec(t, formatTable, "user", "set-role", id.String()), "usage: user set-role USER_ID ROLE")
+	assert.ErrorContains(t, f.exec(t, formatTable, "user", "set-role", "nope", "moderator"), "invalid user id")
+	f.users.AssertNumberOfCalls(t, "SetRole", 1)
+}
+
+func TestPVZList_Flags(t *testing.T) {
+	f := newFixture()
+	registered := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	id := uuid.New()
+	f.pvz.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 2, Limit: 5}).
+		Return(&domain.PVZPage{
+			Items: []domain.PVZWithReceptions{{
+				PVZ:        domain.PVZ{ID: id, City: "Москва", RegistrationDate: registered},
+				Receptions: make([]domain.ReceptionWithProducts, 3),
+			}},
+			Total:   11,
+			HasMore: true,
+		}, nil)
+
+	err := f.exec(t, formatTable, "pvz", "list", "-page", "2", "-limit", "5")
+
+	assert.NoError(t, err)
+	assert.Contains(t, f.out.String(), id.String()+"  Москва  2025-04-01 12:00:00  3")
+	assert.Equal(t, "1 of 11 shown, next page: -page 3\n", f.hints.String())
+	f.pvz.AssertExpectations(t)
+}
+
+func TestPVZList_DefaultsAndJSONHasNoHint(t *testing.T) {
+	f := newFixture()
+	f.pvz.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 1, Limit: 30}).
+		Return(&domain.PVZPage{HasMore: true}, nil)
+
+	assert.NoError(t, f.exec(t, formatJSON, "pvz", "list"))
+	assert.Equal(t, "[]\n", f.out.String())
+	assert.Empty(t, f.hints.String())
+}
+
+func TestPVZList_BadFlag(t *testing.T) {
+	f := newFixture()
+
+	err := f.exec(t, formatTable, "pvz", "list", "-page", "two")
+
+	assert.Error(t, err)
+	f.pvz.AssertNotCalled(t, "ListPVZWithFilter")
+}
+
+func TestPVZCreate(t *testing.T) {
+	f := newFixture()
+	f.pvz.On("CreatePVZ", mock.Anything, "Казань", operator).
+		Return(&domain.PVZ{ID: uuid.New(), City: "Казань"}, nil)
+
+	assert.NoError(t, f.exec(t, formatTable, "pvz", "create", "Казань"))
+	assert.Contains(t, f.out.String(), "Казань")
+	assert.EqualError(t, f.exec(t, formatTable, "pvz", "create"), "usage: pvz create CITY")
+}
+
+func TestReceptionClose_ServiceError(t *testing.T) {
+	f := newFixture()
+	id := uuid.New()
+	f.receptions.On("ForceCloseReception", mock.Anything, id, operator).Return(nil, errors.New("приемка уже закрыта"))
+
+	err := f.exec(t, formatTable, "reception", "close", id.String())
+
+	assert.EqualError(t, err, "приемка уже закрыта")
+	assert.Empty(t, f.out.String())
+}
+
+func TestReceptionProducts(t *testing.T) {
+	f := newFixture()
+	id := uuid.New()
+	added := time.Date(2025, 4, 2, 9, 30, 0, 0, time.UTC)
+	f.receptions.On("GetReception", mock.Anything, id, operator).
+		Return(&domain.ReceptionWithProducts{
+			Products: []domain.Product{{ID: uuid.New(), Type: "обувь", DateTime: added}},
+		}, nil)
+
+	assert.NoError(t, f.exec(t, formatTable, "reception", "products", id.String()))
+	lines := strings.Split(strings.TrimSpace(f.out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "ID"))
+	assert.Contains(t, lines[1], "обувь  2025-04-02 09:30:00")
+
+	assert.ErrorContains(t, f.exec(t, formatTable, "reception", "products", "x"), "invalid reception id")
+}
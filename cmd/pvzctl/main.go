@@ -0,0 +1,165 @@
+// Command pvzctl is the operator CLI for user, PVZ and reception maintenance.
+// It talks to the database directly through the same services as the API,
+// acting as a moderator, and reads its settings like the server does.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/authz"
+	"pvs/internal/config"
+	"pvs/internal/domain"
+	"pvs/internal/repository/postgres"
+	"pvs/internal/service"
+)
+
+const usage = `usage: pvzctl [-o table|json] <command> [args]
+
+commands:
+  user create -email EMAIL -role employee|moderator
+  user list
+  user set-role USER_ID ROLE
+  pvz create CITY
+  pvz list [-page N] [-limit N]
+  reception close RECEPTION_ID
+  reception products RECEPTION_ID
+
+The database and password policy come from the same environment variables
+and CONFIG_FILE as the server; only the database settings are validated.
+user create reads the password from the first line of stdin, so it never
+shows up in the process list or shell history.
+`
+
+// operator is the principal pvzctl acts as. It has no user ID, so audit
+// columns written by it stay empty.
+var operator = domain.Principal{Email: "pvzctl", Role: authz.RoleModerator}
+
+type userService interface {
+	CreateUser(ctx context.Context, email, password, role string, principal domain.Principal) (*domain.User, error)
+	ListUsers(ctx context.Context, principal domain.Principal) ([]domain.User, error)
+	SetRole(ctx context.Context, id uuid.UUID, role string, principal domain.Principal) (*domain.User, error)
+}
+
+type pvzService interface {
+	CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error)
+	ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error)
+}
+
+type receptionService interface {
+	ForceCloseReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.Reception, error)
+	GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error)
+}
+
+type services struct {
+	users      userService
+	pvz        pvzService
+	receptions receptionService
+}
+
+var errUsage = errors.New("usage")
+
+func main() {
+	args, format, err := parseArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
+	}
+	if err != nil {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(args, format); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs splits the global flags from the command and its arguments.
+func parseArgs(args []string) ([]string, string, error) {
+	fs := flag.NewFlagSet("pvzctl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("o", formatTable, "output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if fs.NArg() < 2 || (*format != formatTable && *format != formatJSON) {
+		return nil, "", errUsage
+	}
+	return fs.Args(), *format, nil
+}
+
+type command func(ctx context.Context, s *services, out *printer, args []string) error
+
+var commands = map[string]command{
+	"user create":        createUser,
+	"user list":          listUsers,
+	"user set-role":      setRole,
+	"pvz create":         createPVZ,
+	"pvz list":           listPVZ,
+	"reception close":    closeReception,
+	"reception products": listProducts,
+}
+
+// lookup finds the command named by the first two arguments.
+func lookup(args []string) (command, error) {
+	name := strings.Join(args[:min(len(args), 2)], " ")
+	cmd, ok := commands[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q, see pvzctl -h", name)
+	}
+	return cmd, nil
+}
+
+func run(args []string, format string) error {
+	cmd, err := lookup(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadDatabase(nil)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("pgxpool.New: %w", err)
+	}
+	defer db.Close()
+
+	return cmd(ctx, newServices(db, cfg), newPrinter(os.Stdout, os.Stderr, format), args[2:])
+}
+
+func newServices(db *pgxpool.Pool, cfg *config.Config) *services {
+	userRepo := postgres.NewUserRepository(db)
+	pvzRepo := postgres.NewPVSRepository(db)
+	receptionRepo := postgres.NewReceptionRepository(db)
+	productRepo := postgres.NewProductRepository(db)
+	staffRepo := postgres.NewStaffRepository(db)
+	transactor := postgres.NewTransactor(db)
+	cityCatalog := service.NewCityCatalog(postgres.NewCityRepository(db), cfg.CityCacheTTL)
+
+	return &services{
+		users: service.NewUserService(userRepo, service.PasswordPolicy{
+			MinLength:     cfg.PasswordMinLength,
+			RequireLetter: cfg.PasswordRequireLetter,
+			RequireDigit:  cfg.PasswordRequireDigit,
+		}),
+		pvz:        service.NewPVSService(pvzRepo, receptionRepo, productRepo, cityCatalog),
+		receptions: service.NewReceptionService(receptionRepo, pvzRepo, productRepo, staffRepo, transactor),
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	args, format, err := parseArgs([]string{"-o", "json", "pvz", "list", "-page", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, formatJSON, format)
+	assert.Equal(t, []string{"pvz", "list", "-page", "2"}, args)
+
+	args, format, err = parseArgs([]string{"user", "list"})
+	assert.NoError(t, err)
+	assert.Equal(t, formatTable, format)
+	assert.Equal(t, []string{"user", "list"}, args)
+}
+
+func TestParseArgs_Invalid(t *testing.T) {
+	cases := map[string][]string{
+		"no command":     {},
+		"no subcommand":  {"user"},
+		"unknown format": {"-o", "yaml", "user", "list"},
+	}
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := parseArgs(args)
+			assert.ErrorIs(t, err, errUsage)
+		})
+	}
+
+	_, _, err := parseArgs([]string{"-h"})
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestLookup(t *testing.T) {
+	for name := range commands {
+		t.Run(name, func(t *testing.T) {
+			cmd, err := lookup(append(strings.Fields(name), "extra"))
+			assert.NoError(t, err)
+			assert.NotNil(t, cmd)
+		})
+	}
+
+	_, err := lookup([]string{"user", "delete"})
+	assert.EqualError(t, err, `unknown command "user delete", see pvzctl -h`)
+}
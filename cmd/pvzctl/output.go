@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"pvs/internal/domain"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type printer struct {
+	w      io.Writer
+	hints  io.Writer
+	format string
+}
+
+func newPrinter(w, hints io.Writer, format string) *printer {
+	return &printer{w: w, hints: hints, format: format}
+}
+
+type row interface {
+	columns() []string
+	values() []string
+}
+
+// render writes items as an aligned table or as a JSON array.
+func render[T row](p *printer, items []T) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		if items == nil {
+			items = []T{}
+		}
+		return enc.Encode(items)
+	}
+
+	var zero T
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(zero.columns(), "\t"))
+	for _, item := range items {
+		fmt.Fprintln(tw, strings.Join(item.values(), "\t"))
+	}
+	return tw.Flush()
+}
+
+type userRow struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Role  string    `json:"role"`
+}
+
+func newUserRow(u domain.User) userRow {
+	return userRow{ID: u.ID, Email: u.Email, Role: u.Role}
+}
+
+func (userRow) columns() []string { return []string{"ID", "EMAIL", "ROLE"} }
+func (r userRow) values() []string {
+	return []string{r.ID.String(), r.Email, r.Role}
+}
+
+type pvzRow struct {
+	ID               uuid.UUID `json:"id"`
+	City             string    `json:"city"`
+	RegistrationDate time.Time `json:"registrationDate"`
+	Receptions       int       `json:"receptions"`
+}
+
+func (pvzRow) columns() []string { return []string{"ID", "CITY", "REGISTERED", "RECEPTIONS"} }
+func (r pvzRow) values() []string {
+	return []string{r.ID.String(), r.City, r.RegistrationDate.Format(time.DateTime), fmt.Sprint(r.Receptions)}
+}
+
+type receptionRow struct {
+	ID       uuid.UUID  `json:"id"`
+	PVZID    uuid.UUID  `json:"pvzId"`
+	DateTime time.Time  `json:"dateTime"`
+	Status   string     `json:"status"`
+	ClosedBy *uuid.UUID `json:"closedBy,omitempty"`
+}
+
+func newReceptionRow(r domain.Reception) receptionRow {
+	return receptionRow{ID: r.ID, PVZID: r.PVZID, DateTime: r.DateTime, Status: r.Status, ClosedBy: r.ClosedBy}
+}
+
+func (receptionRow) columns() []string { return []string{"ID", "PVZ", "OPENED", "STATUS"} }
+func (r receptionRow) values() []string {
+	return []string{r.ID.String(), r.PVZID.String(), r.DateTime.Format(time.DateTime), r.Status}
+}
+
+type productRow struct {
+	ID       uuid.UUID `json:"id"`
+	Type     string    `json:"type"`
+	DateTime time.Time `json:"dateTime"`
+}
+
+func (productRow) columns() []string { return []string{"ID", "TYPE", "ADDED"} }
+func (r productRow) values() []string {
+	return []string{r.ID.String(), r.Type, r.DateTime.Format(time.DateTime)}
+}
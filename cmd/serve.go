@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"pvs/internal/app"
+	"pvs/internal/config"
+	"syscall"
+)
+
+func serve(args []string) error {
+	cfg, err := config.Load(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	a, err := app.NewApp(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to start app: %w", err)
+	}
+
+	go func() {
+		if err := a.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("server error: %v", err)
+		}
+	}()
+
+	go func() {
+		if err := a.RunGRPC(); err != nil {
+			log.Fatalf("grpc server error: %v", err)
+		}
+	}()
+
+	go func() {
+		if err := a.RunMetrics(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("metrics server error: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	// A second signal kills the process instead of waiting for the drain.
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownDelay+cfg.ShutdownTimeout)
+	defer cancel()
+	if err := a.Stop(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shutdown: %w", err)
+	}
+	return nil
+}
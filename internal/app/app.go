@@ -2,99 +2,312 @@ package app
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
-	"os"
-	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/pressly/goose/v3"
+	"google.golang.org/grpc"
 
 	"pvs/internal/config"
-	"pvs/internal/controller"
+	"pvs/internal/health"
+	"pvs/internal/metrics"
+	"pvs/internal/migrate"
 	"pvs/internal/repository/postgres"
 	"pvs/internal/service"
+	"pvs/internal/transport/grpcserver"
 	"pvs/internal/transport/middleware"
+	"pvs/migrations"
 )
 
+const readinessTimeout = 2 * time.Second
+
 type App struct {
-	Server *http.Server
-	DB     *pgxpool.Pool
-}
+	Server        *http.Server
+	MetricsServer *http.Server
+	GRPCServer    *grpc.Server
+	GRPCAddr      string
+	DB            *pgxpool.Pool
+	// DrainDelay is how long Stop keeps serving after readiness turns
+	// false, so that load balancers stop routing before connections close.
+	DrainDelay time.Duration
 
-func NewApp(ctx context.Context) (*App, error) {
-	cfg := config.Load()
+	ready       atomic.Bool
+	workerCtx   context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+}
 
-	db, err := pgxpool.New(ctx, cfg.DatabaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("pgxpool.New: %w", err)
+func NewApp(ctx context.Context, cfg *config.Config) (_ *App, err error) {
+	log.Printf("🌍 environment: %s", cfg.Env)
+	if cfg.TestRoutesEnabled() {
+		log.Println("⚠️  test-only routes (/dummyLogin) are enabled")
 	}
 
-	execPath, err := os.Getwd()
+	poolCfg, err := pgxpool.ParseConfig(cfg.DatabaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("os.Getwd failed: %w", err)
+		return nil, fmt.Errorf("pgxpool.ParseConfig: %w", err)
 	}
+	poolCfg.MaxConns = int32(cfg.DBMaxConns)
+	poolCfg.MinConns = int32(cfg.DBMinConns)
 
-	migrationsPath := filepath.Join(execPath, "migrations")
-
-	sqlDB, err := sql.Open("pgx", cfg.DatabaseURL)
+	db, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
-		return nil, fmt.Errorf("sql.Open: %w", err)
+		return nil, fmt.Errorf("pgxpool.New: %w", err)
 	}
-	defer sqlDB.Close()
-
-	log.Println("📦 applying migrations from:", migrationsPath)
+	defer func() {
+		if err != nil {
+			db.Close()
+		}
+	}()
 
-	if err := goose.Up(sqlDB, migrationsPath); err != nil {
-		return nil, fmt.Errorf("goose.Up: %w", err)
+	if cfg.MigrateOnStart {
+		if err := migrateUp(ctx, cfg.DatabaseURL); err != nil {
+			return nil, err
+		}
 	}
 
 	userRepo := postgres.NewUserRepository(db)
 	pvzRepo := postgres.NewPVSRepository(db)
 	receptionRepo := postgres.NewReceptionRepository(db)
 	productRepo := postgres.NewProductRepository(db)
+	productTypeRepo := postgres.NewProductTypeRepository(db)
+	cityRepo := postgres.NewCityRepository(db)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	revokedTokenRepo := postgres.NewRevokedTokenRepository(db)
+	loginAttemptRepo := postgres.NewLoginAttemptRepository(db)
+	staffRepo := postgres.NewStaffRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	tokens := service.NewTokenIssuer([]byte(cfg.JWTSecret), cfg.JWTKeyID, cfg.JWTIssuer, cfg.JWTAudience, cfg.AccessTokenTTL)
+	revocations := service.NewRevocationList(revokedTokenRepo, cfg.RevocationCacheTTL)
+	loginLimiter := service.NewLoginLimiter(loginAttemptRepo, service.LoginThrottle{
+		MaxFailures:   cfg.LoginMaxFailures,
+		MaxIPFailures: cfg.LoginMaxIPFailures,
+		Window:        cfg.LoginFailureWindow,
+		Lockout:       cfg.LoginLockout,
+		MaxLockout:    cfg.LoginMaxLockout,
+	})
+	passwords := service.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireLetter: cfg.PasswordRequireLetter,
+		RequireDigit:  cfg.PasswordRequireDigit,
+	}
+	authService := service.NewAuthService(
+		userRepo,
+		tokens,
+		refreshTokenRepo,
+		revocations,
+		loginLimiter,
+		transactor,
+		passwords,
+		cfg.RefreshTokenTTL,
+	)
+	cityCatalog := service.NewCityCatalog(cityRepo, cfg.CityCacheTTL)
+	pvzService := service.NewPVSService(pvzRepo, receptionRepo, productRepo, cityCatalog)
+	receptionService := service.NewReceptionService(receptionRepo, pvzRepo, productRepo, staffRepo, transactor)
+	productService := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, staffRepo, transactor)
+	productTypeService := service.NewProductTypeService(productTypeRepo)
+	cityService := service.NewCityService(cityRepo, cityCatalog)
+	staffService := service.NewStaffService(staffRepo, userRepo)
+
+	// Tokens issued before kid was stamped carry none and were signed with
+	// the active secret, so it also verifies the empty kid.
+	keys := middleware.NewHMACKeySet([]byte(cfg.JWTSecret))
+	keys.AddHMAC(cfg.JWTKeyID, []byte(cfg.JWTSecret))
+	for kid, secret := range cfg.JWTPreviousSecrets {
+		keys.AddHMAC(kid, []byte(secret))
+	}
+	for kid, path := range cfg.JWTPublicKeyFiles {
+		if err := keys.AddPublicKeyFile(kid, path); err != nil {
+			return nil, fmt.Errorf("jwt keys: %w", err)
+		}
+	}
 
-	authService := service.NewAuthService(userRepo, []byte(cfg.JWTSecret))
-	pvzService := service.NewPVSService(pvzRepo)
-	receptionService := service.NewReceptionService(receptionRepo)
-	productService := service.NewProductService(productRepo, receptionRepo)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
+	migrationRepo := postgres.NewMigrationRepository(db)
 
-	mux := http.NewServeMux()
+	readiness := health.NewChecker(readinessTimeout)
+	readiness.Add("postgres", db.Ping)
+	readiness.Add("migrations", health.MigrationCheck(migrationRepo.Version, migrations.FS))
 
-	mux.HandleFunc("POST /dummyLogin", controller.DummyLoginHandler([]byte(cfg.JWTSecret)))
-	mux.HandleFunc("POST /register", controller.RegisterHandler(authService))
-	mux.HandleFunc("POST /login", controller.LoginHandler(authService))
+	authConfig := middleware.AuthConfig{
+		Keys:        keys,
+		Issuer:      cfg.JWTIssuer,
+		Audience:    cfg.JWTAudience,
+		Revocations: revocations,
+	}
+	router := NewRouter(RouterConfig{
+		DummyLogin:     cfg.TestRoutesEnabled(),
+		ClientIPHeader: cfg.TrustedProxyHeader,
+		DummyAuth:      authService,
+		Auth:           authConfig,
+		Idempotency:    middleware.NewIdempotencyMiddleware(idempotencyRepo, cfg.IdempotencyTTL),
+		Readiness:      readiness,
+		MaxBodyBytes:   cfg.MaxBodyBytes,
+	}, Services{
+		Auth:        authService,
+		PVZ:         pvzService,
+		Reception:   receptionService,
+		Product:     productService,
+		ProductType: productTypeService,
+		City:        cityService,
+		Staff:       staffService,
+	})
 
-	protected := http.NewServeMux()
-	protected.HandleFunc("POST /pvz", controller.CreatePVZHandler(pvzService))
-	protected.HandleFunc("GET /pvz", controller.GetPVZListHandler(pvzService))
+	server := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           router,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
 
-	protected.HandleFunc("POST /receptions", controller.CreateReceptionHandler(receptionService))
-	protected.HandleFunc("POST /pvz/{pvzId}/close_last_reception", controller.CloseLastReceptionHandler(receptionService))
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("GET /metrics", metrics.Handler())
 
-	protected.HandleFunc("POST /products", controller.AddProductHandler(productService))
-	protected.HandleFunc("POST /pvz/{pvzId}/delete_last_product", controller.DeleteLastProductHandler(productService))
+	metricsServer := &http.Server{
+		Addr:    ":" + cfg.MetricsPort,
+		Handler: metricsMux,
+	}
 
-	mux.Handle("/", middleware.AuthMiddleware(protected))
+	grpcServer := grpcserver.NewServer(pvzService, middleware.NewAuthenticator(authConfig))
 
-	server := &http.Server{
-		Addr:    ":8080",
-		Handler: mux,
+	a := &App{
+		Server:        server,
+		MetricsServer: metricsServer,
+		GRPCServer:    grpcServer,
+		GRPCAddr:      ":" + cfg.GRPCPort,
+		DB:            db,
+		DrainDelay:    cfg.ShutdownDelay,
 	}
+	readiness.Add("server", func(context.Context) error {
+		if !a.Ready() {
+			return errors.New("not serving")
+		}
+		return nil
+	})
+	a.startWorker(ctx, func(ctx context.Context) {
+		pruneExpired(ctx, "tokens", authService.PruneExpired, pruneInterval)
+	})
+	a.startWorker(ctx, func(ctx context.Context) {
+		pruneExpired(ctx, "idempotency keys", idempotencyRepo.DeleteExpired, pruneInterval)
+	})
+	a.startWorker(ctx, func(ctx context.Context) {
+		pruneExpired(ctx, "login attempts", loginLimiter.PruneStale, pruneInterval)
+	})
+	return a, nil
+}
 
-	return &App{Server: server, DB: db}, nil
+func migrateUp(ctx context.Context, databaseURL string) error {
+	m, err := migrate.Open(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.Up(ctx)
 }
 
 func (a *App) Run() error {
-	log.Println("✅ Server running on :8080")
-	return a.Server.ListenAndServe()
+	lis, err := net.Listen("tcp", a.Server.Addr)
+	if err != nil {
+		return fmt.Errorf("net.Listen: %w", err)
+	}
+	return a.Serve(lis)
+}
+
+// Serve accepts HTTP connections on lis and marks the app ready. Like
+// http.Server.Serve it returns http.ErrServerClosed after Stop.
+func (a *App) Serve(lis net.Listener) error {
+	log.Println("✅ Server running on", lis.Addr())
+	a.ready.Store(true)
+	return a.Server.Serve(lis)
+}
+
+// Ready reports whether the app accepts traffic. It turns false as soon as
+// Stop begins, before any connection is closed.
+func (a *App) Ready() bool {
+	return a.ready.Load()
+}
+
+func (a *App) RunMetrics() error {
+	log.Println("✅ Metrics server running on", a.MetricsServer.Addr)
+	return a.MetricsServer.ListenAndServe()
+}
+
+func (a *App) RunGRPC() error {
+	lis, err := net.Listen("tcp", a.GRPCAddr)
+	if err != nil {
+		return fmt.Errorf("net.Listen: %w", err)
+	}
+	log.Println("✅ gRPC server running on", a.GRPCAddr)
+	return a.GRPCServer.Serve(lis)
 }
 
+// Stop shuts the app down in dependency order: it reports not ready and
+// keeps serving for DrainDelay, stops accepting and drains HTTP and gRPC
+// until ctx expires, then stops background workers and only after that
+// closes the database pool, so in-flight requests never see a closed pool.
 func (a *App) Stop(ctx context.Context) error {
 	log.Println("🛑 Shutting down...")
-	a.DB.Close()
-	return a.Server.Shutdown(ctx)
+	a.ready.Store(false)
+
+	if a.DrainDelay > 0 {
+		log.Printf("⏳ Waiting %s before draining", a.DrainDelay)
+		select {
+		case <-time.After(a.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	var errs []error
+	grpcDone := make(chan error, 1)
+	go func() { grpcDone <- stopGRPC(ctx, a.GRPCServer) }()
+
+	if err := a.Server.Shutdown(ctx); err != nil {
+		a.Server.Close()
+		errs = append(errs, fmt.Errorf("http shutdown: %w", err))
+	}
+	if err := <-grpcDone; err != nil {
+		errs = append(errs, fmt.Errorf("grpc shutdown: %w", err))
+	}
+	if err := a.MetricsServer.Shutdown(ctx); err != nil {
+		a.MetricsServer.Close()
+		errs = append(errs, fmt.Errorf("metrics server shutdown: %w", err))
+	}
+
+	if a.stopWorkers != nil {
+		a.stopWorkers()
+	}
+	a.workers.Wait()
+
+	if a.DB != nil {
+		a.DB.Close()
+	}
+	log.Println("👋 Shutdown complete")
+	return errors.Join(errs...)
+}
+
+func stopGRPC(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	close(h.started)
+	<-h.release
+	w.WriteHeader(http.StatusOK)
+}
+
+func startTestApp(t *testing.T, handler http.Handler) (*App, string, chan error) {
+	t.Helper()
+
+	a := &App{
+		Server:        &http.Server{Handler: handler},
+		MetricsServer: &http.Server{},
+		GRPCServer:    grpc.NewServer(),
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- a.Serve(lis) }()
+	require.Eventually(t, a.Ready, time.Second, time.Millisecond)
+	return a, "http://" + lis.Addr().String(), served
+}
+
+func TestStop_DrainsInFlightRequestsBeforeWorkers(t *testing.T) {
+	h := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	a, url, served := startTestApp(t, h)
+
+	workerStopped := make(chan struct{})
+	a.startWorker(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(workerStopped)
+	})
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-h.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- a.Stop(ctx) }()
+
+	require.Eventually(t, func() bool { return !a.Ready() }, time.Second, time.Millisecond)
+	select {
+	case <-workerStopped:
+		t.Fatal("worker stopped while a request was still in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(h.release)
+	assert.Equal(t, http.StatusOK, <-status)
+	assert.NoError(t, <-stopped)
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+	<-workerStopped
+}
+
+func TestStop_DeadlineForcesClose(t *testing.T) {
+	h := &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(h.release)
+	a, url, _ := startTestApp(t, h)
+
+	workerStopped := make(chan struct{})
+	a.startWorker(context.Background(), func(ctx context.Context) {
+		<-ctx.Done()
+		close(workerStopped)
+	})
+
+	go http.Get(url)
+	<-h.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := a.Stop(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, a.Ready())
+	<-workerStopped
+}
+
+func TestStartWorker_OutlivesAppContext(t *testing.T) {
+	a := &App{
+		Server:        &http.Server{},
+		MetricsServer: &http.Server{},
+		GRPCServer:    grpc.NewServer(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	workerStopped := make(chan struct{})
+	a.startWorker(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+		close(workerStopped)
+	})
+	cancel()
+
+	select {
+	case <-workerStopped:
+		t.Fatal("worker stopped with the app context")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, a.Stop(context.Background()))
+	<-workerStopped
+}
+
+func TestStop_ServesDuringDrainDelay(t *testing.T) {
+	a, url, served := startTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	a.DrainDelay = 200 * time.Millisecond
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- a.Stop(context.Background()) }()
+	require.Eventually(t, func() bool { return !a.Ready() }, time.Second, time.Millisecond)
+
+	resp, err := http.Get(url)
+	require.NoError(t, err, "new requests are still accepted while not ready")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.NoError(t, <-stopped)
+	assert.ErrorIs(t, <-served, http.ErrServerClosed)
+}
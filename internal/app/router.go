@@ -0,0 +1,92 @@
+package app
+
+import (
+	"net/http"
+
+	"pvs/internal/authz"
+	"pvs/internal/controller"
+	"pvs/internal/health"
+	"pvs/internal/transport/middleware"
+)
+
+type Services struct {
+	Auth        controller.AuthServiceInterface
+	PVZ         controller.PVZServiceInterface
+	Reception   controller.ReceptionServiceInterface
+	Product     controller.ProductServiceInterface
+	ProductType controller.ProductTypeServiceInterface
+	City        controller.CityServiceInterface
+	Staff       controller.StaffServiceInterface
+}
+
+type RouterConfig struct {
+	DummyLogin bool
+	// ClientIPHeader names the header a trusted proxy puts the client IP
+	// in; empty means the connection address is used.
+	ClientIPHeader string
+	DummyAuth      controller.DummyLoginService
+	Auth           middleware.AuthConfig
+	Idempotency    func(http.Handler) http.Handler
+	Readiness      *health.Checker
+	// MaxBodyBytes caps request bodies; zero leaves them unlimited.
+	MaxBodyBytes int64
+}
+
+func NewRouter(cfg RouterConfig, s Services) http.Handler {
+	mux := http.NewServeMux()
+
+	readiness := cfg.Readiness
+	if readiness == nil {
+		readiness = health.NewChecker(readinessTimeout)
+	}
+	mux.Handle("GET /healthz", health.LivenessHandler())
+	mux.Handle("GET /readyz", readiness.ReadinessHandler())
+
+	if cfg.DummyLogin {
+		mux.HandleFunc("POST /dummyLogin", controller.DummyLoginHandler(cfg.DummyAuth))
+	}
+	mux.HandleFunc("POST /register", controller.RegisterHandler(s.Auth))
+	mux.HandleFunc("POST /login", controller.LoginHandler(s.Auth, cfg.ClientIPHeader))
+	mux.HandleFunc("POST /token/refresh", controller.RefreshTokenHandler(s.Auth))
+
+	authenticate := middleware.NewAuthMiddleware(cfg.Auth)
+	auth := func(next http.Handler) http.Handler {
+		return authenticate(middleware.AuditMiddleware(next))
+	}
+	idempotent := cfg.Idempotency
+	if idempotent == nil {
+		idempotent = func(next http.Handler) http.Handler { return next }
+	}
+
+	mux.Handle("POST /logout", auth(controller.LogoutHandler(s.Auth)))
+
+	mux.Handle("POST /pvz", auth(middleware.Require(authz.CreatePVZ)(idempotent(controller.CreatePVZHandler(s.PVZ)))))
+	mux.Handle("GET /pvz", auth(middleware.Require(authz.ListPVZ)(controller.GetPVZListHandler(s.PVZ))))
+
+	mux.Handle("GET /pvz/{pvzId}/staff", auth(middleware.Require(authz.ManageStaff)(controller.ListStaffHandler(s.Staff))))
+	mux.Handle("POST /pvz/{pvzId}/staff", auth(middleware.Require(authz.ManageStaff)(idempotent(controller.AssignStaffHandler(s.Staff)))))
+	mux.Handle("DELETE /pvz/{pvzId}/staff/{userId}", auth(middleware.Require(authz.ManageStaff)(controller.UnassignStaffHandler(s.Staff))))
+
+	mux.Handle("POST /receptions", auth(middleware.Require(authz.OpenReception)(idempotent(controller.CreateReceptionHandler(s.Reception)))))
+	mux.Handle("GET /pvz/{pvzId}/receptions", auth(middleware.Require(authz.ViewReceptions)(controller.ListReceptionsHandler(s.Reception))))
+	mux.Handle("GET /receptions/{id}", auth(middleware.Require(authz.ViewReceptions)(controller.GetReceptionHandler(s.Reception))))
+	mux.Handle("POST /pvz/{pvzId}/close_last_reception", auth(middleware.Require(authz.CloseReception)(idempotent(controller.CloseLastReceptionHandler(s.Reception)))))
+
+	mux.Handle("POST /products", auth(middleware.Require(authz.AddProduct)(idempotent(controller.AddProductHandler(s.Product)))))
+	mux.Handle("POST /pvz/{pvzId}/delete_last_product", auth(middleware.Require(authz.DeleteProduct)(idempotent(controller.DeleteLastProductHandler(s.Product)))))
+
+	mux.Handle("GET /product_types", auth(middleware.Require(authz.ListProductTypes)(controller.ListProductTypesHandler(s.ProductType))))
+	mux.Handle("POST /product_types", auth(middleware.Require(authz.ManageProductType)(idempotent(controller.CreateProductTypeHandler(s.ProductType)))))
+	mux.Handle("PATCH /product_types/{name}", auth(middleware.Require(authz.ManageProductType)(controller.UpdateProductTypeHandler(s.ProductType))))
+	mux.Handle("DELETE /product_types/{name}", auth(middleware.Require(authz.ManageProductType)(controller.DeleteProductTypeHandler(s.ProductType))))
+
+	mux.Handle("GET /cities", auth(middleware.Require(authz.ListCities)(controller.ListCitiesHandler(s.City))))
+	mux.Handle("POST /cities", auth(middleware.Require(authz.ManageCity)(idempotent(controller.CreateCityHandler(s.City)))))
+	mux.Handle("PATCH /cities/{name}", auth(middleware.Require(authz.ManageCity)(controller.UpdateCityHandler(s.City))))
+
+	var handler http.Handler = mux
+	if cfg.MaxBodyBytes > 0 {
+		handler = middleware.LimitBody(cfg.MaxBodyBytes)(mux)
+	}
+	return middleware.MetricsMiddleware(handler)
+}
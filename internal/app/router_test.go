@@ -0,0 +1,214 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/app"
+	"pvs/internal/controller"
+	"pvs/internal/domain"
+	"pvs/internal/health"
+	"pvs/internal/service"
+	"pvs/internal/transport/middleware"
+)
+
+var jwtSecret = []byte("configured-secret")
+
+type mockPVZService struct {
+	mock.Mock
+}
+
+func (m *mockPVZService) CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error) {
+	args := m.Called(ctx, city, principal)
+	if pvz := args.Get(0); pvz != nil {
+		return pvz.(*domain.PVZ), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPVZService) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error) {
+	args := m.Called(ctx, filter)
+	if page := args.Get(0); page != nil {
+		return page.(*domain.PVZPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type mockReceptionService struct {
+	mock.Mock
+}
+
+func (m *mockReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, principal)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal, idempotencyKey string) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, principal, idempotencyKey)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionService) ListReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter, principal domain.Principal) (*domain.ReceptionPage, error) {
+	args := m.Called(ctx, pvzID, filter, principal)
+	if page := args.Get(0); page != nil {
+		return page.(*domain.ReceptionPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionService) GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error) {
+	args := m.Called(ctx, id, principal)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.ReceptionWithProducts), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// dummyAuth issues /dummyLogin tokens without the users row AuthService
+// creates for them.
+type dummyAuth struct {
+	tokens *service.TokenIssuer
+}
+
+func (d dummyAuth) DummyLogin(_ context.Context, role string) (string, error) {
+	return d.tokens.IssueDummy(service.DummyUser(role))
+}
+
+func newRouter(s app.Services) http.Handler {
+	return app.NewRouter(app.RouterConfig{
+		DummyLogin: true,
+		DummyAuth:  dummyAuth{service.NewTokenIssuer(jwtSecret, "", "pvz-test", "pvz-test-api", time.Hour)},
+		Auth: middleware.AuthConfig{
+			Keys:     middleware.NewHMACKeySet(jwtSecret),
+			Issuer:   "pvz-test",
+			Audience: "pvz-test-api",
+		},
+	}, s)
+}
+
+func dummyToken(t *testing.T, router http.Handler, role string) string {
+	body, _ := json.Marshal(controller.DummyLoginRequest{Role: role})
+	req := httptest.NewRequest(http.MethodPost, "/dummyLogin", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp controller.AuthTokenResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp.Token
+}
+
+func doRequest(router http.Handler, method, path, token string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouter_ModeratorCanCreatePVZ(t *testing.T) {
+	pvzService := new(mockPVZService)
+	router := newRouter(app.Services{PVZ: pvzService})
+
+	pvzService.On("CreatePVZ", mock.Anything, "Москва", mock.Anything).Return(&domain.PVZ{ID: uuid.New(), City: "Москва"}, nil)
+
+	token := dummyToken(t, router, "moderator")
+	w := doRequest(router, http.MethodPost, "/pvz", token, []byte(`{"city":"Москва"}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	pvzService.AssertExpectations(t)
+}
+
+func TestRouter_EmployeeCannotCreatePVZ(t *testing.T) {
+	pvzService := new(mockPVZService)
+	router := newRouter(app.Services{PVZ: pvzService})
+
+	token := dummyToken(t, router, "employee")
+	w := doRequest(router, http.MethodPost, "/pvz", token, []byte(`{"city":"Москва"}`))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	pvzService.AssertNotCalled(t, "CreatePVZ", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRouter_PrincipalPropagatesToService(t *testing.T) {
+	receptionService := new(mockReceptionService)
+	router := newRouter(app.Services{Reception: receptionService})
+
+	pvzID := uuid.New()
+	dummy := service.DummyUser("employee")
+	receptionService.On("CreateReception", mock.Anything, pvzID, mock.MatchedBy(func(p domain.Principal) bool {
+		return p.Role == "employee" && p.UserID == dummy.ID && p.Email == dummy.Email && p.TokenID != ""
+	})).
+		Return(&domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: "in_progress"}, nil)
+
+	token := dummyToken(t, router, "employee")
+	body, _ := json.Marshal(map[string]any{"pvzId": pvzID})
+	w := doRequest(router, http.MethodPost, "/receptions", token, body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	receptionService.AssertExpectations(t)
+}
+
+func TestRouter_MissingToken(t *testing.T) {
+	router := newRouter(app.Services{})
+
+	w := doRequest(router, http.MethodGet, "/pvz", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRouter_HealthWithoutToken(t *testing.T) {
+	readiness := health.NewChecker(time.Second)
+	readiness.Add("postgres", func(context.Context) error { return errors.New("down") })
+	router := app.NewRouter(app.RouterConfig{Readiness: readiness}, app.Services{})
+
+	w := doRequest(router, http.MethodGet, "/healthz", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doRequest(router, http.MethodGet, "/readyz", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"postgres":{"status":"fail","error":"check failed"}`)
+}
+
+func TestRouter_DummyLoginDisabled(t *testing.T) {
+	router := app.NewRouter(app.RouterConfig{
+		Auth: middleware.AuthConfig{Keys: middleware.NewHMACKeySet(jwtSecret)},
+	}, app.Services{})
+
+	w := doRequest(router, http.MethodPost, "/dummyLogin", "", []byte(`{"role":"moderator"}`))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRouter_DummyTokenIsMarked(t *testing.T) {
+	receptionService := new(mockReceptionService)
+	router := newRouter(app.Services{Reception: receptionService})
+
+	pvzID := uuid.New()
+	receptionService.On("CreateReception", mock.Anything, pvzID, mock.MatchedBy(func(p domain.Principal) bool {
+		return p.Dummy
+	})).Return(&domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: "in_progress"}, nil)
+
+	token := dummyToken(t, router, "employee")
+	body, _ := json.Marshal(map[string]any{"pvzId": pvzID})
+	w := doRequest(router, http.MethodPost, "/receptions", token, body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	receptionService.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const pruneInterval = time.Hour
+
+// startWorker runs fn in the background until Stop. Workers outlive the
+// ctx passed to NewApp so that a signal does not stop them before the
+// servers have drained.
+func (a *App) startWorker(ctx context.Context, fn func(ctx context.Context)) {
+	if a.workerCtx == nil {
+		a.workerCtx, a.stopWorkers = context.WithCancel(context.WithoutCancel(ctx))
+	}
+	a.workers.Add(1)
+	go func() {
+		defer a.workers.Done()
+		fn(a.workerCtx)
+	}()
+}
+
+// pruneExpired calls prune every interval until ctx is done; what names the
+// pruned records in the error log.
+func pruneExpired(ctx context.Context, what string, prune func(context.Context) error, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := prune(ctx); err != nil {
+				log.Printf("❌ prune expired %s: %v", what, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package authz
+
+import (
+	"errors"
+	"slices"
+
+	"pvs/internal/domain"
+)
+
+const (
+	RoleEmployee  = "employee"
+	RoleModerator = "moderator"
+)
+
+type Action string
+
+const (
+	ListPVZ   Action = "pvz:list"
+	CreatePVZ Action = "pvz:create"
+
+	OpenReception       Action = "reception:open"
+	CloseReception      Action = "reception:close"
+	ViewReceptions      Action = "reception:view"
+	ViewAnyReceptions   Action = "reception:view_any"
+	ForceCloseReception Action = "reception:force_close"
+
+	AddProduct    Action = "product:add"
+	DeleteProduct Action = "product:delete"
+
+	ListProductTypes  Action = "product_type:list"
+	ManageProductType Action = "product_type:manage"
+
+	ListCities Action = "city:list"
+	ManageCity Action = "city:manage"
+
+	ManageStaff Action = "staff:manage"
+	ManageUsers Action = "user:manage"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// permissions is the single source of truth for who may do what. A new role
+// is introduced by adding it to Roles and to the actions it is granted.
+var permissions = map[Action][]string{
+	ListPVZ:   {RoleEmployee, RoleModerator},
+	CreatePVZ: {RoleModerator},
+
+	OpenReception:       {RoleEmployee},
+	CloseReception:      {RoleEmployee},
+	ViewReceptions:      {RoleEmployee, RoleModerator},
+	ViewAnyReceptions:   {RoleModerator},
+	ForceCloseReception: {RoleModerator},
+
+	AddProduct:    {RoleEmployee},
+	DeleteProduct: {RoleEmployee},
+
+	ListProductTypes:  {RoleEmployee, RoleModerator},
+	ManageProductType: {RoleModerator},
+
+	ListCities: {RoleEmployee, RoleModerator},
+	ManageCity: {RoleModerator},
+
+	ManageStaff: {RoleModerator},
+	ManageUsers: {RoleModerator},
+}
+
+var Roles = []string{RoleEmployee, RoleModerator}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+func Can(role string, action Action) bool {
+	return slices.Contains(permissions[action], role)
+}
+
+func Check(principal domain.Principal, action Action) error {
+	if !Can(principal.Role, action) {
+		return ErrForbidden
+	}
+	return nil
+}
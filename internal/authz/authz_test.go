@@ -0,0 +1,51 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+)
+
+func TestCan(t *testing.T) {
+	tests := []struct {
+		role    string
+		action  authz.Action
+		allowed bool
+	}{
+		{authz.RoleModerator, authz.CreatePVZ, true},
+		{authz.RoleEmployee, authz.CreatePVZ, false},
+		{authz.RoleEmployee, authz.ListPVZ, true},
+		{authz.RoleEmployee, authz.OpenReception, true},
+		{authz.RoleModerator, authz.OpenReception, false},
+		{authz.RoleEmployee, authz.AddProduct, true},
+		{authz.RoleModerator, authz.ManageStaff, true},
+		{authz.RoleEmployee, authz.ManageStaff, false},
+		{authz.RoleModerator, authz.ManageUsers, true},
+		{authz.RoleEmployee, authz.ManageUsers, false},
+		{authz.RoleModerator, authz.ForceCloseReception, true},
+		{authz.RoleEmployee, authz.ForceCloseReception, false},
+		{"", authz.ListPVZ, false},
+		{"auditor", authz.ListPVZ, false},
+		{authz.RoleModerator, authz.Action("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+string(tt.action), func(t *testing.T) {
+			assert.Equal(t, tt.allowed, authz.Can(tt.role, tt.action))
+		})
+	}
+}
+
+func TestValidRole(t *testing.T) {
+	assert.True(t, authz.ValidRole(authz.RoleEmployee))
+	assert.True(t, authz.ValidRole(authz.RoleModerator))
+	assert.False(t, authz.ValidRole("admin"))
+	assert.False(t, authz.ValidRole(""))
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, authz.Check(domain.Principal{Role: authz.RoleModerator}, authz.ManageCity))
+	assert.ErrorIs(t, authz.Check(domain.Principal{Role: authz.RoleEmployee}, authz.ManageCity), authz.ErrForbidden)
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/config"
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.HTTPAddr)
+	assert.Equal(t, config.EnvProd, cfg.Env)
+	assert.False(t, cfg.TestRoutesEnabled())
+	assert.Equal(t, 10, cfg.DBMaxConns)
+}
+
+func TestLoad_DefaultSecretRequiresDev(t *testing.T) {
+	_, err := config.Load(nil)
+	assert.ErrorContains(t, err, "jwt_secret")
+
+	t.Setenv("APP_ENV", config.EnvDev)
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.TestRoutesEnabled())
+}
+
+func TestTestRoutesEnabled_OnlyInDev(t *testing.T) {
+	for env, want := range map[string]bool{config.EnvDev: true, config.EnvStaging: false, config.EnvProd: false} {
+		cfg := config.Default()
+		cfg.Env = env
+		assert.Equal(t, want, cfg.TestRoutesEnabled(), env)
+	}
+}
+
+func TestLoad_PortBuildsAddr(t *testing.T) {
+	t.Setenv("APP_ENV", config.EnvDev)
+	t.Setenv("PORT", "9090")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.HTTPAddr)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, `
+http_read_timeout: 3s
+http_write_timeout: 4s
+db_max_conns: 20
+`)
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("APP_ENV", config.EnvDev)
+	t.Setenv("HTTP_WRITE_TIMEOUT", "6s")
+	t.Setenv("DB_MAX_CONNS", "30")
+
+	cfg, err := config.Load([]string{"-db-max-conns", "40", "-addr", "127.0.0.1:8000"})
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, cfg.HTTPReadTimeout, "file overrides default")
+	assert.Equal(t, 6*time.Second, cfg.HTTPWriteTimeout, "env overrides file")
+	assert.Equal(t, 40, cfg.DBMaxConns, "flag overrides env")
+	assert.Equal(t, "127.0.0.1:8000", cfg.HTTPAddr)
+}
+
+func TestLoad_UnknownFileKey(t *testing.T) {
+	path := writeFile(t, "http_timeout: 3s\n")
+
+	_, err := config.Load([]string{"-config", path})
+	assert.ErrorContains(t, err, "http_timeout")
+}
+
+func TestLoad_ReportsAllErrors(t *testing.T) {
+	t.Setenv("PORT", "http")
+	t.Setenv("ACCESS_TOKEN_TTL", "soon")
+
+	_, err := config.Load(nil)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "PORT")
+	assert.ErrorContains(t, err, "ACCESS_TOKEN_TTL")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*config.Config)
+		want   string
+	}{
+		{"unknown env", func(c *config.Config) { c.Env = "qa" }, "env"},
+		{"default secret in prod", func(c *config.Config) { c.Env = config.EnvProd }, "jwt_secret"},
+		{"default secret in staging", func(c *config.Config) { c.Env = config.EnvStaging }, "jwt_secret"},
+		{"min conns above max", func(c *config.Config) { c.DBMinConns = 11 }, "db_min_conns"},
+		{"ip threshold below email threshold", func(c *config.Config) { c.LoginMaxIPFailures = 2 }, "login_max_ip_failures"},
+		{"zero body limit", func(c *config.Config) { c.MaxBodyBytes = 0 }, "max_body_bytes"},
+		{"refresh shorter than access", func(c *config.Config) { c.RefreshTokenTTL = time.Minute }, "refresh_token_ttl"},
+		{"bad grpc port", func(c *config.Config) { c.GRPCPort = "70000" }, "grpc_port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Env = config.EnvDev
+			cfg.HTTPAddr = ":8080"
+			tt.mutate(cfg)
+			assert.ErrorContains(t, cfg.Validate(), tt.want)
+		})
+	}
+}
+
+func TestLoadDatabase_IgnoresServerSettings(t *testing.T) {
+	t.Setenv("ACCESS_TOKEN_TTL", "0s")
+
+	cfg, err := config.LoadDatabase(nil)
+	require.NoError(t, err, "the default secret in prod and a bad token TTL do not matter")
+	assert.Equal(t, config.EnvProd, cfg.Env)
+
+	t.Setenv("DB_MAX_CONNS", "0")
+	_, err = config.LoadDatabase(nil)
+	assert.ErrorContains(t, err, "db_max_conns")
+}
+
+func TestLoad_PreviousSecrets(t *testing.T) {
+	t.Setenv("APP_ENV", config.EnvDev)
+	t.Setenv("JWT_KEY_ID", "k2")
+	t.Setenv("JWT_PREVIOUS_SECRETS", "k1=old-secret, k0=older-secret")
+
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", cfg.JWTKeyID)
+	assert.Equal(t, map[string]string{"k1": "old-secret", "k0": "older-secret"}, cfg.JWTPreviousSecrets)
+
+	t.Setenv("JWT_PREVIOUS_SECRETS", "k2=old-secret")
+	_, err = config.Load(nil)
+	assert.ErrorContains(t, err, `kid "k2" is the active jwt_key_id`)
+}
+
+func TestLoad_PreviousSecretsErrorHidesValue(t *testing.T) {
+	t.Setenv("APP_ENV", config.EnvDev)
+	t.Setenv("JWT_PREVIOUS_SECRETS", "k1=old-secret,leaked-secret")
+
+	_, err := config.Load(nil)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "old-secret")
+	assert.NotContains(t, err.Error(), "leaked-secret")
+	assert.ErrorContains(t, err, "JWT_PREVIOUS_SECRETS: entry 2 is not kid=")
+}
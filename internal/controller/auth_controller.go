@@ -3,13 +3,25 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
-	"pvs/internal/service"
+	"strings"
+
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
 )
 
 type AuthServiceInterface interface {
-	Register(ctx context.Context, email, password, role string) (string, error)
-	Login(ctx context.Context, email, password string) (string, error)
+	Register(ctx context.Context, email, password, role string) (*domain.TokenPair, error)
+	Login(ctx context.Context, email, password, clientIP string) (*domain.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error)
+	Logout(ctx context.Context, principal domain.Principal, refreshToken string) error
+}
+
+// DummyLoginService is only wired up when test routes are enabled.
+type DummyLoginService interface {
+	DummyLogin(ctx context.Context, role string) (string, error)
 }
 
 type DummyLoginRequest struct {
@@ -22,29 +34,43 @@ type AuthRequest struct {
 	Role     string `json:"role,omitempty"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type AuthTokenResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int64  `json:"expiresIn,omitempty"`
+}
+
+func tokenResponse(pair *domain.TokenPair) AuthTokenResponse {
+	return AuthTokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    int64(pair.ExpiresIn.Seconds()),
+	}
 }
 
-func DummyLoginHandler(secret []byte) http.HandlerFunc {
+func DummyLoginHandler(auth DummyLoginService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req DummyLoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid request", http.StatusBadRequest)
+			writeBadRequest(w, "invalid request")
 			return
 		}
-		if req.Role != "employee" && req.Role != "moderator" {
-			http.Error(w, "invalid role", http.StatusBadRequest)
+		if !authz.ValidRole(req.Role) {
+			writeBadRequest(w, "invalid role")
 			return
 		}
 
-		token, err := service.GenerateToken(secret, req.Role)
+		token, err := auth.DummyLogin(r.Context(), req.Role)
 		if err != nil {
-			http.Error(w, "token error", http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(AuthTokenResponse{Token: token})
+		writeJSON(w, http.StatusOK, AuthTokenResponse{Token: token})
 	}
 }
 
@@ -52,35 +78,87 @@ func RegisterHandler(auth AuthServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req AuthRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid request", http.StatusBadRequest)
+			writeBadRequest(w, "invalid request")
 			return
 		}
-		if req.Role != "employee" && req.Role != "moderator" {
-			http.Error(w, "invalid role", http.StatusBadRequest)
+		if !authz.ValidRole(req.Role) {
+			writeBadRequest(w, "invalid role")
 			return
 		}
-		token, err := auth.Register(r.Context(), req.Email, req.Password, req.Role)
+		pair, err := auth.Register(r.Context(), req.Email, req.Password, req.Role)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(AuthTokenResponse{Token: token})
+		writeJSON(w, http.StatusCreated, tokenResponse(pair))
 	}
 }
 
-func LoginHandler(auth AuthServiceInterface) http.HandlerFunc {
+// LoginHandler takes the client IP for login throttling from ipHeader when
+// it is set, which must only be done behind a proxy that overwrites it, and
+// from the connection otherwise.
+func LoginHandler(auth AuthServiceInterface, ipHeader string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req AuthRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid request", http.StatusBadRequest)
+			writeBadRequest(w, "invalid request")
 			return
 		}
-		token, err := auth.Login(r.Context(), req.Email, req.Password)
+		pair, err := auth.Login(r.Context(), req.Email, req.Password, clientIP(r, ipHeader))
 		if err != nil {
-			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tokenResponse(pair))
+	}
+}
+
+func clientIP(r *http.Request, ipHeader string) string {
+	if ipHeader != "" {
+		// X-Forwarded-For style lists end with the address seen by our proxy
+		values := strings.Split(r.Header.Get(ipHeader), ",")
+		if ip := net.ParseIP(strings.TrimSpace(values[len(values)-1])); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func RefreshTokenHandler(auth AuthServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "invalid request")
+			return
+		}
+		pair, err := auth.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tokenResponse(pair))
+	}
+}
+
+func LogoutHandler(auth AuthServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshTokenRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeBadRequest(w, "invalid request")
+				return
+			}
+		}
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		if err := auth.Logout(r.Context(), principal, req.RefreshToken); err != nil {
+			writeError(w, err)
 			return
 		}
-		json.NewEncoder(w).Encode(AuthTokenResponse{Token: token})
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
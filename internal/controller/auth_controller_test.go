@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"pvs/internal/controller"
+	"pvs/internal/domain"
+	svc "pvs/internal/service"
+	"pvs/internal/transport/middleware"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -19,33 +22,54 @@ type mockAuthService struct {
 	mock.Mock
 }
 
-func (m *mockAuthService) Register(ctx context.Context, email, password, role string) (string, error) {
-	args := m.Called(ctx, email, password, role)
-	return args.String(0), args.Error(1)
+func tokenPair(args mock.Arguments) (*domain.TokenPair, error) {
+	if pair := args.Get(0); pair != nil {
+		return pair.(*domain.TokenPair), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockAuthService) Register(ctx context.Context, email, password, role string) (*domain.TokenPair, error) {
+	return tokenPair(m.Called(ctx, email, password, role))
+}
+
+func (m *mockAuthService) Login(ctx context.Context, email, password, clientIP string) (*domain.TokenPair, error) {
+	return tokenPair(m.Called(ctx, email, password, clientIP))
 }
 
-func (m *mockAuthService) Login(ctx context.Context, email, password string) (string, error) {
-	args := m.Called(ctx, email, password)
+func (m *mockAuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	return tokenPair(m.Called(ctx, refreshToken))
+}
+
+func (m *mockAuthService) Logout(ctx context.Context, principal domain.Principal, refreshToken string) error {
+	args := m.Called(ctx, principal, refreshToken)
+	return args.Error(0)
+}
+
+func (m *mockAuthService) DummyLogin(ctx context.Context, role string) (string, error) {
+	args := m.Called(ctx, role)
 	return args.String(0), args.Error(1)
 }
 
 func TestDummyLoginHandler_Success(t *testing.T) {
+	auth := new(mockAuthService)
+	auth.On("DummyLogin", mock.Anything, "employee").Return("dummy-token", nil)
+
 	body := []byte(`{"role":"employee"}`)
 	req := httptest.NewRequest(http.MethodPost, "/dummy-login", bytes.NewReader(body))
 	w := httptest.NewRecorder()
 
-	handler := controller.DummyLoginHandler([]byte("secret"))
-	handler(w, req)
+	controller.DummyLoginHandler(auth)(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	var resp controller.AuthTokenResponse
 	_ = json.NewDecoder(w.Body).Decode(&resp)
-	assert.NotEmpty(t, resp.Token)
+	assert.Equal(t, "dummy-token", resp.Token)
 }
 
 func TestRegisterHandler_Success(t *testing.T) {
 	auth := new(mockAuthService)
-	auth.On("Register", mock.Anything, "test@mail.com", "123", "employee").Return("token123", nil)
+	auth.On("Register", mock.Anything, "test@mail.com", "123", "employee").Return(&domain.TokenPair{AccessToken: "token123", RefreshToken: "refresh123", ExpiresIn: 15 * time.Minute}, nil)
 
 	reqBody := []byte(`{"email":"test@mail.com","password":"123","role":"employee"}`)
 	req := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(reqBody))
@@ -58,18 +82,100 @@ func TestRegisterHandler_Success(t *testing.T) {
 	var resp controller.AuthTokenResponse
 	_ = json.NewDecoder(w.Body).Decode(&resp)
 	assert.Equal(t, "token123", resp.Token)
+	assert.Equal(t, "refresh123", resp.RefreshToken)
+	assert.Equal(t, int64(900), resp.ExpiresIn)
 }
 
 func TestLoginHandler_InvalidCredentials(t *testing.T) {
 	auth := new(mockAuthService)
-	auth.On("Login", mock.Anything, "test@mail.com", "wrongpass").Return("", errors.New("invalid"))
+	auth.On("Login", mock.Anything, "test@mail.com", "wrongpass", "192.0.2.1").Return(nil, svc.ErrInvalidCredentials)
 
 	reqBody := []byte(`{"email":"test@mail.com","password":"wrongpass"}`)
 	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(reqBody))
 	w := httptest.NewRecorder()
 
-	handler := controller.LoginHandler(auth)
+	handler := controller.LoginHandler(auth, "")
 	handler(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestRefreshTokenHandler_Invalid(t *testing.T) {
+	auth := new(mockAuthService)
+	auth.On("Refresh", mock.Anything, "stale").Return(nil, svc.ErrInvalidRefreshToken)
+
+	req := httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewReader([]byte(`{"refreshToken":"stale"}`)))
+	w := httptest.NewRecorder()
+	controller.RefreshTokenHandler(auth)(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var resp controller.ErrorResponse
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, "invalid_refresh_token", resp.Code)
+}
+
+func TestLogoutHandler_RevokesCurrentToken(t *testing.T) {
+	auth := new(mockAuthService)
+	principal := domain.Principal{Role: "employee", TokenID: "jti-1"}
+	auth.On("Logout", mock.Anything, principal, "refresh123").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", bytes.NewReader([]byte(`{"refreshToken":"refresh123"}`)))
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	w := httptest.NewRecorder()
+	controller.LogoutHandler(auth)(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	auth.AssertExpectations(t)
+}
+
+func TestLogoutHandler_WithoutBody(t *testing.T) {
+	auth := new(mockAuthService)
+	principal := domain.Principal{Role: "employee", TokenID: "jti-1"}
+	auth.On("Logout", mock.Anything, principal, "").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+	w := httptest.NewRecorder()
+	controller.LogoutHandler(auth)(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestLoginHandler_TooManyAttempts(t *testing.T) {
+	auth := new(mockAuthService)
+	auth.On("Login", mock.Anything, "test@mail.com", "pass", "192.0.2.1").Return(nil, svc.ErrTooManyLoginAttempts)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte(`{"email":"test@mail.com","password":"pass"}`)))
+	w := httptest.NewRecorder()
+	controller.LoginHandler(auth, "")(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestLoginHandler_ClientIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		ipHeader string
+		header   string
+		want     string
+	}{
+		{"remote addr without header config", "", "203.0.113.7", "192.0.2.1"},
+		{"configured header", "X-Real-IP", "203.0.113.7", "203.0.113.7"},
+		{"last forwarded entry", "X-Forwarded-For", "198.51.100.1, 203.0.113.7", "203.0.113.7"},
+		{"garbage falls back to remote addr", "X-Real-IP", "not-an-ip", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := new(mockAuthService)
+			auth.On("Login", mock.Anything, "test@mail.com", "pass", tt.want).Return(nil, svc.ErrInvalidCredentials)
+
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte(`{"email":"test@mail.com","password":"pass"}`)))
+			req.Header.Set("X-Real-IP", tt.header)
+			req.Header.Set("X-Forwarded-For", tt.header)
+			controller.LoginHandler(auth, tt.ipHeader)(httptest.NewRecorder(), req)
+
+			auth.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+type CreateCityRequest struct {
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type UpdateCityRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type CityServiceInterface interface {
+	ListCities(ctx context.Context) ([]domain.City, error)
+	CreateCity(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error)
+	SetCityEnabled(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error)
+}
+
+func ListCitiesHandler(s CityServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cities, err := s.ListCities(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, cities)
+	}
+}
+
+func CreateCityHandler(s CityServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateCityRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "неверный формат запроса")
+			return
+		}
+
+		enabled := true
+		if req.Enabled != nil {
+			enabled = *req.Enabled
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		city, err := s.CreateCity(r.Context(), req.Name, enabled, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, city)
+	}
+}
+
+func UpdateCityHandler(s CityServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateCityRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			writeBadRequest(w, "неверный формат запроса")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		city, err := s.SetCityEnabled(r.Context(), r.PathValue("name"), *req.Enabled, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, city)
+	}
+}
@@ -0,0 +1,102 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/controller"
+	"pvs/internal/domain"
+	svc "pvs/internal/service"
+)
+
+type mockCityService struct {
+	mock.Mock
+}
+
+func (m *mockCityService) ListCities(ctx context.Context) ([]domain.City, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.City), args.Error(1)
+}
+
+func (m *mockCityService) CreateCity(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error) {
+	args := m.Called(ctx, name, enabled, principal)
+	if city := args.Get(0); city != nil {
+		return city.(*domain.City), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockCityService) SetCityEnabled(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error) {
+	args := m.Called(ctx, name, enabled, principal)
+	if city := args.Get(0); city != nil {
+		return city.(*domain.City), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestCreateCityHandler_DefaultsToEnabled(t *testing.T) {
+	service := new(mockCityService)
+	handler := controller.CreateCityHandler(service)
+
+	service.On("CreateCity", mock.Anything, "Новосибирск", true, domain.Principal{Role: "moderator"}).
+		Return(&domain.City{Name: "Новосибирск", Enabled: true}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cities", bytes.NewReader([]byte(`{"name":"Новосибирск"}`)))
+	req = req.WithContext(withRole(req.Context(), "moderator"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestUpdateCityHandler_Disable(t *testing.T) {
+	service := new(mockCityService)
+	mux := http.NewServeMux()
+	mux.Handle("PATCH /cities/{name}", controller.UpdateCityHandler(service))
+
+	service.On("SetCityEnabled", mock.Anything, "Казань", false, domain.Principal{Role: "moderator"}).
+		Return(&domain.City{Name: "Казань", Enabled: false}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/cities/Казань", bytes.NewReader([]byte(`{"enabled":false}`)))
+	req = req.WithContext(withRole(req.Context(), "moderator"))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp domain.City
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.False(t, resp.Enabled)
+}
+
+func TestUpdateCityHandler_MissingEnabled(t *testing.T) {
+	service := new(mockCityService)
+	handler := controller.UpdateCityHandler(service)
+
+	req := httptest.NewRequest(http.MethodPatch, "/cities/Казань", bytes.NewReader([]byte(`{}`)))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "SetCityEnabled", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateCityHandler_Forbidden(t *testing.T) {
+	service := new(mockCityService)
+	handler := controller.CreateCityHandler(service)
+
+	service.On("CreateCity", mock.Anything, "Новосибирск", true, domain.Principal{Role: "employee"}).Return(nil, svc.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodPost, "/cities", bytes.NewReader([]byte(`{"name":"Новосибирск"}`)))
+	req = req.WithContext(withRole(req.Context(), "employee"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
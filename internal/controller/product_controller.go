@@ -15,25 +15,24 @@ type AddProductRequest struct {
 }
 
 type ProductServiceInterface interface {
-	AddProduct(ctx context.Context, pvzID uuid.UUID, productType, role string) (*domain.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, role string) error
+	AddProduct(ctx context.Context, pvzID uuid.UUID, productType string, principal domain.Principal) (*domain.Product, error)
+	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) error
 }
 
 func AddProductHandler(s ProductServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req AddProductRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "неверный запрос", http.StatusBadRequest)
+			writeBadRequest(w, "неверный запрос")
 			return
 		}
-		role := middleware.GetUserRole(r.Context())
-		product, err := s.AddProduct(r.Context(), req.PVZID, req.Type, role)
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		product, err := s.AddProduct(r.Context(), req.PVZID, req.Type, principal)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(product)
+		writeJSON(w, http.StatusCreated, product)
 	}
 }
 
@@ -42,12 +41,12 @@ func DeleteLastProductHandler(s ProductServiceInterface) http.HandlerFunc {
 		pvzIDStr := r.PathValue("pvzId")
 		pvzID, err := uuid.Parse(pvzIDStr)
 		if err != nil {
-			http.Error(w, "неверный UUID", http.StatusBadRequest)
+			writeBadRequest(w, "неверный UUID")
 			return
 		}
-		role := middleware.GetUserRole(r.Context())
-		if err := s.DeleteLastProduct(r.Context(), pvzID, role); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		if err := s.DeleteLastProduct(r.Context(), pvzID, principal); err != nil {
+			writeError(w, err)
 			return
 		}
 		w.WriteHeader(http.StatusOK)
@@ -14,22 +14,23 @@ import (
 	"github.com/stretchr/testify/mock"
 	"pvs/internal/controller"
 	"pvs/internal/domain"
+	svc "pvs/internal/service"
 )
 
 type mockProductService struct {
 	mock.Mock
 }
 
-func (m *mockProductService) AddProduct(ctx context.Context, pvzID uuid.UUID, productType, role string) (*domain.Product, error) {
-	args := m.Called(ctx, pvzID, productType, role)
+func (m *mockProductService) AddProduct(ctx context.Context, pvzID uuid.UUID, productType string, principal domain.Principal) (*domain.Product, error) {
+	args := m.Called(ctx, pvzID, productType, principal)
 	if p := args.Get(0); p != nil {
 		return p.(*domain.Product), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *mockProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, role string) error {
-	args := m.Called(ctx, pvzID, role)
+func (m *mockProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) error {
+	args := m.Called(ctx, pvzID, principal)
 	return args.Error(0)
 }
 
@@ -51,7 +52,7 @@ func TestAddProductHandler_Success(t *testing.T) {
 		Type:        "shoes",
 		ReceptionID: uuid.New(),
 	}
-	service.On("AddProduct", mock.Anything, pvzID, "shoes", "employee").Return(expected, nil)
+	service.On("AddProduct", mock.Anything, pvzID, "shoes", employeePrincipal).Return(expected, nil)
 
 	body, _ := json.Marshal(map[string]any{
 		"pvzId": pvzID,
@@ -76,7 +77,7 @@ func TestAddProductHandler_ServiceError(t *testing.T) {
 	handler := controller.AddProductHandler(service)
 
 	pvzID := uuid.New()
-	service.On("AddProduct", mock.Anything, pvzID, "toys", "employee").Return(nil, errors.New("fail"))
+	service.On("AddProduct", mock.Anything, pvzID, "toys", employeePrincipal).Return(nil, svc.ErrNoOpenReception)
 
 	body, _ := json.Marshal(map[string]any{
 		"pvzId": pvzID,
@@ -88,8 +89,12 @@ func TestAddProductHandler_ServiceError(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	handler(w, req)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "fail")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var resp controller.ErrorResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "no_open_reception", resp.Code)
+	assert.Equal(t, "нет активной приёмки", resp.Message)
 }
 
 func TestDeleteLastProductHandler_BadUUID(t *testing.T) {
@@ -107,7 +112,7 @@ func TestDeleteLastProductHandler_Success(t *testing.T) {
 	handler := controller.DeleteLastProductHandler(service)
 
 	pvzID := uuid.New()
-	service.On("DeleteLastProduct", mock.Anything, pvzID, "employee").Return(nil)
+	service.On("DeleteLastProduct", mock.Anything, pvzID, employeePrincipal).Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/delete_last_product", nil)
 	req.SetPathValue("pvzId", pvzID.String())
@@ -123,7 +128,7 @@ func TestDeleteLastProductHandler_ServiceError(t *testing.T) {
 	handler := controller.DeleteLastProductHandler(service)
 
 	pvzID := uuid.New()
-	service.On("DeleteLastProduct", mock.Anything, pvzID, "employee").Return(errors.New("fail"))
+	service.On("DeleteLastProduct", mock.Anything, pvzID, employeePrincipal).Return(errors.New("fail"))
 
 	req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/delete_last_product", nil)
 	req.SetPathValue("pvzId", pvzID.String())
@@ -131,6 +136,7 @@ func TestDeleteLastProductHandler_ServiceError(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	handler(w, req)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "internal_error")
+	assert.NotContains(t, w.Body.String(), "fail")
 }
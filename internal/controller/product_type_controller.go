@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+type CreateProductTypeRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateProductTypeRequest struct {
+	Name string `json:"name"`
+}
+
+type ProductTypeServiceInterface interface {
+	ListProductTypes(ctx context.Context) ([]domain.ProductType, error)
+	CreateProductType(ctx context.Context, name string, principal domain.Principal) (*domain.ProductType, error)
+	RenameProductType(ctx context.Context, name, newName string, principal domain.Principal) (*domain.ProductType, error)
+	DeleteProductType(ctx context.Context, name string, principal domain.Principal) error
+}
+
+func ListProductTypesHandler(s ProductTypeServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		types, err := s.ListProductTypes(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, types)
+	}
+}
+
+func CreateProductTypeHandler(s ProductTypeServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req CreateProductTypeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "неверный формат запроса")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		pt, err := s.CreateProductType(r.Context(), req.Name, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, pt)
+	}
+}
+
+func UpdateProductTypeHandler(s ProductTypeServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req UpdateProductTypeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeBadRequest(w, "неверный формат запроса")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		pt, err := s.RenameProductType(r.Context(), r.PathValue("name"), req.Name, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, pt)
+	}
+}
+
+func DeleteProductTypeHandler(s ProductTypeServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		if err := s.DeleteProductType(r.Context(), r.PathValue("name"), principal); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,124 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/controller"
+	"pvs/internal/domain"
+	svc "pvs/internal/service"
+)
+
+type mockProductTypeService struct {
+	mock.Mock
+}
+
+func (m *mockProductTypeService) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.ProductType), args.Error(1)
+}
+
+func (m *mockProductTypeService) CreateProductType(ctx context.Context, name string, principal domain.Principal) (*domain.ProductType, error) {
+	args := m.Called(ctx, name, principal)
+	if pt := args.Get(0); pt != nil {
+		return pt.(*domain.ProductType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockProductTypeService) RenameProductType(ctx context.Context, name, newName string, principal domain.Principal) (*domain.ProductType, error) {
+	args := m.Called(ctx, name, newName, principal)
+	if pt := args.Get(0); pt != nil {
+		return pt.(*domain.ProductType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockProductTypeService) DeleteProductType(ctx context.Context, name string, principal domain.Principal) error {
+	args := m.Called(ctx, name, principal)
+	return args.Error(0)
+}
+
+func TestListProductTypesHandler_Success(t *testing.T) {
+	service := new(mockProductTypeService)
+	handler := controller.ListProductTypesHandler(service)
+
+	service.On("ListProductTypes", mock.Anything).Return([]domain.ProductType{{Name: "обувь"}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/product_types", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp []domain.ProductType
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, "обувь", resp[0].Name)
+}
+
+func TestCreateProductTypeHandler_Success(t *testing.T) {
+	service := new(mockProductTypeService)
+	handler := controller.CreateProductTypeHandler(service)
+
+	service.On("CreateProductType", mock.Anything, "книги", domain.Principal{Role: "moderator"}).Return(&domain.ProductType{Name: "книги"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/product_types", bytes.NewReader([]byte(`{"name":"книги"}`)))
+	req = req.WithContext(withRole(req.Context(), "moderator"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestCreateProductTypeHandler_Forbidden(t *testing.T) {
+	service := new(mockProductTypeService)
+	handler := controller.CreateProductTypeHandler(service)
+
+	service.On("CreateProductType", mock.Anything, "книги", domain.Principal{Role: "employee"}).Return(nil, svc.ErrForbidden)
+
+	req := httptest.NewRequest(http.MethodPost, "/product_types", bytes.NewReader([]byte(`{"name":"книги"}`)))
+	req = req.WithContext(withRole(req.Context(), "employee"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteProductTypeHandler_InUse(t *testing.T) {
+	service := new(mockProductTypeService)
+	handler := controller.DeleteProductTypeHandler(service)
+
+	service.On("DeleteProductType", mock.Anything, "обувь", domain.Principal{Role: "moderator"}).Return(svc.ErrProductTypeInUse)
+
+	req := httptest.NewRequest(http.MethodDelete, "/product_types/обувь", nil)
+	req.SetPathValue("name", "обувь")
+	req = req.WithContext(withRole(req.Context(), "moderator"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "product_type_in_use")
+}
+
+func TestUpdateProductTypeHandler_Success(t *testing.T) {
+	service := new(mockProductTypeService)
+	handler := controller.UpdateProductTypeHandler(service)
+
+	service.On("RenameProductType", mock.Anything, "обувь", "ботинки", domain.Principal{Role: "moderator"}).Return(&domain.ProductType{Name: "ботинки"}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/product_types/обувь", bytes.NewReader([]byte(`{"name":"ботинки"}`)))
+	req.SetPathValue("name", "обувь")
+	req = req.WithContext(withRole(req.Context(), "moderator"))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ботинки")
+	service.AssertExpectations(t)
+}
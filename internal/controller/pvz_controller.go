@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"pvs/internal/domain"
 	"pvs/internal/transport/middleware"
 	"strconv"
@@ -15,72 +16,104 @@ type CreatePVZRequest struct {
 }
 
 type PVZServiceInterface interface {
-	CreatePVZ(ctx context.Context, city string) (*domain.PVZ, error)
-	ListPVZWithFilter(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]domain.PVZ, error)
+	CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error)
+	ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error)
 }
 
+const defaultPVZPageLimit = 10
+
 func CreatePVZHandler(s PVZServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		role := middleware.GetUserRole(r.Context())
-		if role != "moderator" {
-			http.Error(w, "только модератор может создавать ПВЗ", http.StatusForbidden)
-			return
-		}
-
 		var req CreatePVZRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "неверный формат запроса", http.StatusBadRequest)
+			writeBadRequest(w, "неверный формат запроса")
 			return
 		}
 
-		pvz, err := s.CreatePVZ(r.Context(), req.City)
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		pvz, err := s.CreatePVZ(r.Context(), req.City, principal)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(pvz)
+		writeJSON(w, http.StatusCreated, pvz)
 	}
 }
 
 func GetPVZListHandler(s PVZServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		query := r.URL.Query()
-		var from, to *time.Time
+		from, ok := timeParam(query, "startDate")
+		if !ok {
+			writeBadRequest(w, "неверный формат startDate")
+			return
+		}
+		to, ok := timeParam(query, "endDate")
+		if !ok {
+			writeBadRequest(w, "неверный формат endDate")
+			return
+		}
 
-		if v := query.Get("startDate"); v != "" {
-			t, err := time.Parse(time.RFC3339, v)
-			if err != nil {
-				http.Error(w, "неверный формат startDate", http.StatusBadRequest)
-				return
-			}
-			from = &t
+		page, ok := intParam(query, "page", 1)
+		if !ok {
+			writeBadRequest(w, "неверный формат page")
+			return
 		}
-		if v := query.Get("endDate"); v != "" {
-			t, err := time.Parse(time.RFC3339, v)
-			if err != nil {
-				http.Error(w, "неверный формат endDate", http.StatusBadRequest)
-				return
-			}
-			to = &t
+		limit, ok := intParam(query, "limit", defaultPVZPageLimit)
+		if !ok {
+			writeBadRequest(w, "неверный формат limit")
+			return
 		}
 
-		page, _ := strconv.Atoi(query.Get("page"))
-		if page < 1 {
-			page = 1
+		filter := domain.PVZFilter{StartDate: from, EndDate: to, Page: page, Limit: limit}
+		responsePage := page
+		if query.Has("cursor") {
+			responsePage = 0
 		}
-		limit, _ := strconv.Atoi(query.Get("limit"))
-		if limit < 1 || limit > 30 {
-			limit = 10
+		if v := query.Get("cursor"); v != "" {
+			after, err := domain.DecodePVZCursor(v)
+			if err != nil {
+				writeBadRequest(w, "неверный cursor")
+				return
+			}
+			filter.After = after
 		}
 
-		result, err := s.ListPVZWithFilter(r.Context(), from, to, page, limit)
+		result, err := s.ListPVZWithFilter(r.Context(), filter)
 		if err != nil {
-			http.Error(w, "ошибка получения списка ПВЗ", http.StatusInternalServerError)
+			writeError(w, err)
 			return
 		}
 
-		json.NewEncoder(w).Encode(result)
+		writeJSON(w, http.StatusOK, ListResponse[domain.PVZWithReceptions]{
+			Items:      result.Items,
+			Page:       responsePage,
+			Limit:      limit,
+			Total:      result.Total,
+			HasMore:    result.HasMore,
+			NextCursor: result.NextCursor,
+		})
+	}
+}
+
+func timeParam(query url.Values, name string) (*time.Time, bool) {
+	raw := query.Get(name)
+	if raw == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, false
+	}
+	return &t, true
+}
+
+func intParam(query url.Values, name string, defaultVal int) (int, bool) {
+	raw := query.Get(name)
+	if raw == "" {
+		return defaultVal, true
 	}
+	val, err := strconv.Atoi(raw)
+	return val, err == nil
 }
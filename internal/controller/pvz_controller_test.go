@@ -3,7 +3,6 @@ package controller_test
 import (
 	"bytes"
 	"context"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,8 +10,10 @@ import (
 
 	"pvs/internal/controller"
 	"pvs/internal/domain"
+	svc "pvs/internal/service"
 	"pvs/internal/transport/middleware"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,25 +22,33 @@ type mockPVZService struct {
 	mock.Mock
 }
 
-func (m *mockPVZService) CreatePVZ(ctx context.Context, city string) (*domain.PVZ, error) {
-	args := m.Called(ctx, city)
+func (m *mockPVZService) CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error) {
+	args := m.Called(ctx, city, principal)
 	if pvz := args.Get(0); pvz != nil {
 		return pvz.(*domain.PVZ), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *mockPVZService) ListPVZWithFilter(ctx context.Context, from, to *time.Time, page, limit int) ([]domain.PVZ, error) {
-	args := m.Called(ctx, from, to, page, limit)
-	return args.Get(0).([]domain.PVZ), args.Error(1)
+func (m *mockPVZService) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error) {
+	args := m.Called(ctx, filter)
+	if page := args.Get(0); page != nil {
+		return page.(*domain.PVZPage), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
+var employeePrincipal = domain.Principal{Role: "employee"}
+
 func withRole(ctx context.Context, role string) context.Context {
-	return context.WithValue(ctx, middleware.RoleCtxKey{}, role)
+	return middleware.WithPrincipal(ctx, domain.Principal{Role: role})
 }
 
 func TestCreatePVZHandler_Forbidden(t *testing.T) {
-	handler := controller.CreatePVZHandler(nil)
+	service := new(mockPVZService)
+	handler := controller.CreatePVZHandler(service)
+
+	service.On("CreatePVZ", mock.Anything, "Москва", employeePrincipal).Return(nil, svc.ErrForbidden)
 
 	req := httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewReader([]byte(`{"city":"Москва"}`)))
 	req = req.WithContext(withRole(req.Context(), "employee")) // not moderator
@@ -47,6 +56,7 @@ func TestCreatePVZHandler_Forbidden(t *testing.T) {
 
 	handler(w, req)
 	assert.Equal(t, http.StatusForbidden, w.Code)
+	service.AssertExpectations(t)
 }
 
 func TestCreatePVZHandler_InvalidJSON(t *testing.T) {
@@ -64,14 +74,15 @@ func TestCreatePVZHandler_ServiceError(t *testing.T) {
 	service := new(mockPVZService)
 	handler := controller.CreatePVZHandler(service)
 
-	service.On("CreatePVZ", mock.Anything, "Казань").Return(nil, errors.New("city error"))
+	service.On("CreatePVZ", mock.Anything, "Казань", mock.Anything).Return(nil, svc.ErrCityNotAllowed)
 
 	req := httptest.NewRequest(http.MethodPost, "/pvz", bytes.NewReader([]byte(`{"city":"Казань"}`)))
 	req = req.WithContext(withRole(req.Context(), "moderator"))
 	w := httptest.NewRecorder()
 
 	handler(w, req)
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "city_not_allowed")
 }
 
 func TestGetPVZListHandler_InvalidStartDate(t *testing.T) {
@@ -83,3 +94,100 @@ func TestGetPVZListHandler_InvalidStartDate(t *testing.T) {
 	handler(w, req)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestGetPVZListHandler_PageEnvelope(t *testing.T) {
+	service := new(mockPVZService)
+	handler := controller.GetPVZListHandler(service)
+
+	service.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 2, Limit: 5}).
+		Return(&domain.PVZPage{Items: []domain.PVZWithReceptions{}, Total: 12, HasMore: true, NextCursor: "next"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?page=2&limit=5", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"page":2,"limit":5,"total":12,"hasMore":true,"next_cursor":"next"}`, w.Body.String())
+}
+
+func TestGetPVZListHandler_DefaultPaging(t *testing.T) {
+	service := new(mockPVZService)
+	handler := controller.GetPVZListHandler(service)
+
+	service.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 1, Limit: 10}).
+		Return(&domain.PVZPage{Items: []domain.PVZWithReceptions{}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"page":1,"limit":10,"total":0,"hasMore":false}`, w.Body.String())
+}
+
+func TestGetPVZListHandler_LimitOutOfRange(t *testing.T) {
+	service := new(mockPVZService)
+	handler := controller.GetPVZListHandler(service)
+
+	service.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 1, Limit: 100}).Return(nil, svc.ErrInvalidInput)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?limit=100", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid_input")
+}
+
+func TestGetPVZListHandler_MalformedLimit(t *testing.T) {
+	handler := controller.GetPVZListHandler(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?limit=ten", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetPVZListHandler_Cursor(t *testing.T) {
+	service := new(mockPVZService)
+	handler := controller.GetPVZListHandler(service)
+
+	after := domain.PVZCursor{RegistrationDate: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC), ID: uuid.New()}
+	service.On("ListPVZWithFilter", mock.Anything, mock.MatchedBy(func(f domain.PVZFilter) bool {
+		return f.After != nil && f.After.ID == after.ID && f.After.RegistrationDate.Equal(after.RegistrationDate) && f.Limit == 10
+	})).Return(&domain.PVZPage{Items: []domain.PVZWithReceptions{}, Total: 3, HasMore: true, NextCursor: "next"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?cursor="+after.Encode(), nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"limit":10,"total":3,"hasMore":true,"next_cursor":"next"}`, w.Body.String())
+	service.AssertExpectations(t)
+}
+
+func TestGetPVZListHandler_FirstCursorPage(t *testing.T) {
+	service := new(mockPVZService)
+	handler := controller.GetPVZListHandler(service)
+
+	service.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 1, Limit: 10}).
+		Return(&domain.PVZPage{Items: []domain.PVZWithReceptions{}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?cursor=", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"limit":10,"total":0,"hasMore":false}`, w.Body.String())
+}
+
+func TestGetPVZListHandler_InvalidCursor(t *testing.T) {
+	handler := controller.GetPVZListHandler(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz?cursor=not-a-cursor", nil)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
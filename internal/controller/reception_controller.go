@@ -14,26 +14,29 @@ type ReceptionCreateRequest struct {
 }
 
 type ReceptionServiceInterface interface {
-	CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error)
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error)
+	CreateReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) (*domain.Reception, error)
+	CloseLastReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal, idempotencyKey string) (*domain.Reception, error)
+	ListReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter, principal domain.Principal) (*domain.ReceptionPage, error)
+	GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error)
 }
 
+const defaultReceptionPageLimit = 10
+
 func CreateReceptionHandler(s ReceptionServiceInterface) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req ReceptionCreateRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "неверный формат запроса", http.StatusBadRequest)
+			writeBadRequest(w, "неверный формат запроса")
 			return
 		}
 
-		role := middleware.GetUserRole(r.Context())
-		reception, err := s.CreateReception(r.Context(), req.PVZID, role)
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		reception, err := s.CreateReception(r.Context(), req.PVZID, principal)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(reception)
+		writeJSON(w, http.StatusCreated, reception)
 	}
 }
 
@@ -42,15 +45,93 @@ func CloseLastReceptionHandler(s ReceptionServiceInterface) http.HandlerFunc {
 		pvzIDStr := r.PathValue("pvzId")
 		pvzID, err := uuid.Parse(pvzIDStr)
 		if err != nil {
-			http.Error(w, "неверный UUID", http.StatusBadRequest)
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+		idempotencyKey := r.Header.Get(middleware.IdempotencyKeyHeader)
+		if len(idempotencyKey) > middleware.MaxIdempotencyKeyLength {
+			writeBadRequest(w, "слишком длинный Idempotency-Key")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		reception, err := s.CloseLastReception(r.Context(), pvzID, principal, idempotencyKey)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reception)
+	}
+}
+
+func ListReceptionsHandler(s ReceptionServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pvzID, err := uuid.Parse(r.PathValue("pvzId"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+
+		query := r.URL.Query()
+		from, ok := timeParam(query, "startDate")
+		if !ok {
+			writeBadRequest(w, "неверный формат startDate")
+			return
+		}
+		to, ok := timeParam(query, "endDate")
+		if !ok {
+			writeBadRequest(w, "неверный формат endDate")
 			return
 		}
-		role := middleware.GetUserRole(r.Context())
-		reception, err := s.CloseLastReception(r.Context(), pvzID, role)
+		page, ok := intParam(query, "page", 1)
+		if !ok {
+			writeBadRequest(w, "неверный формат page")
+			return
+		}
+		limit, ok := intParam(query, "limit", defaultReceptionPageLimit)
+		if !ok {
+			writeBadRequest(w, "неверный формат limit")
+			return
+		}
+
+		filter := domain.ReceptionFilter{
+			Status:    query.Get("status"),
+			StartDate: from,
+			EndDate:   to,
+			Page:      page,
+			Limit:     limit,
+		}
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		result, err := s.ListReceptions(r.Context(), pvzID, filter, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, ListResponse[domain.Reception]{
+			Items:   result.Items,
+			Page:    page,
+			Limit:   limit,
+			Total:   result.Total,
+			HasMore: result.HasMore,
+		})
+	}
+}
+
+func GetReceptionHandler(s ReceptionServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		reception, err := s.GetReception(r.Context(), id, principal)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, err)
 			return
 		}
-		json.NewEncoder(w).Encode(reception)
+		writeJSON(w, http.StatusOK, reception)
 	}
 }
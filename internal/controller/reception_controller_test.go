@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,28 +13,46 @@ import (
 	"github.com/stretchr/testify/mock"
 	"pvs/internal/controller"
 	"pvs/internal/domain"
+	svc "pvs/internal/service"
+	"pvs/internal/transport/middleware"
 )
 
 type mockReceptionService struct {
 	mock.Mock
 }
 
-func (m *mockReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error) {
-	args := m.Called(ctx, pvzID, role)
+func (m *mockReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, principal)
 	if rec := args.Get(0); rec != nil {
 		return rec.(*domain.Reception), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-func (m *mockReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error) {
-	args := m.Called(ctx, pvzID, role)
+func (m *mockReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal, idempotencyKey string) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, principal, idempotencyKey)
 	if rec := args.Get(0); rec != nil {
 		return rec.(*domain.Reception), args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
+func (m *mockReceptionService) ListReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter, principal domain.Principal) (*domain.ReceptionPage, error) {
+	args := m.Called(ctx, pvzID, filter, principal)
+	if page := args.Get(0); page != nil {
+		return page.(*domain.ReceptionPage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionService) GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error) {
+	args := m.Called(ctx, id, principal)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.ReceptionWithProducts), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func TestCreateReceptionHandler_BadJSON(t *testing.T) {
 	handler := controller.CreateReceptionHandler(nil)
 
@@ -52,7 +69,7 @@ func TestCloseLastReceptionHandler_Success(t *testing.T) {
 
 	pvzID := uuid.New()
 	expected := &domain.Reception{ID: uuid.New(), PVZID: pvzID}
-	service.On("CloseLastReception", mock.Anything, pvzID, "employee").Return(expected, nil)
+	service.On("CloseLastReception", mock.Anything, pvzID, employeePrincipal, "").Return(expected, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/reception/"+pvzID.String()+"/close", nil)
 	req.SetPathValue("pvzId", pvzID.String())
@@ -83,7 +100,7 @@ func TestCloseLastReceptionHandler_ServiceError(t *testing.T) {
 	handler := controller.CloseLastReceptionHandler(service)
 
 	pvzID := uuid.New()
-	service.On("CloseLastReception", mock.Anything, pvzID, "employee").Return(nil, errors.New("fail"))
+	service.On("CloseLastReception", mock.Anything, pvzID, employeePrincipal, "").Return(nil, svc.ErrNoOpenReception)
 
 	req := httptest.NewRequest(http.MethodPost, "/reception/"+pvzID.String()+"/close", nil)
 	req.SetPathValue("pvzId", pvzID.String())
@@ -91,5 +108,95 @@ func TestCloseLastReceptionHandler_ServiceError(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	handler(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "no_open_reception")
+}
+
+func TestCloseLastReceptionHandler_PassesIdempotencyKey(t *testing.T) {
+	service := new(mockReceptionService)
+	handler := controller.CloseLastReceptionHandler(service)
+
+	pvzID := uuid.New()
+	expected := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: "close"}
+	service.On("CloseLastReception", mock.Anything, pvzID, employeePrincipal, "retry-1").Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/close_last_reception", nil)
+	req.SetPathValue("pvzId", pvzID.String())
+	req.Header.Set(middleware.IdempotencyKeyHeader, "retry-1")
+	req = req.WithContext(withRole(req.Context(), "employee"))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestListReceptionsHandler_Filters(t *testing.T) {
+	service := new(mockReceptionService)
+	handler := controller.ListReceptionsHandler(service)
+
+	pvzID := uuid.New()
+	service.On("ListReceptions", mock.Anything, pvzID, mock.MatchedBy(func(f domain.ReceptionFilter) bool {
+		return f.Status == "close" && f.StartDate != nil && f.EndDate == nil && f.Page == 2 && f.Limit == 5
+	}), employeePrincipal).Return(&domain.ReceptionPage{Items: []domain.Reception{}, Total: 6}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/receptions?status=close&startDate=2025-04-01T00:00:00Z&page=2&limit=5", nil)
+	req.SetPathValue("pvzId", pvzID.String())
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), employeePrincipal))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"page":2,"limit":5,"total":6,"hasMore":false}`, w.Body.String())
+	service.AssertExpectations(t)
+}
+
+func TestListReceptionsHandler_InvalidEndDate(t *testing.T) {
+	handler := controller.ListReceptionsHandler(nil)
+
+	pvzID := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/pvz/"+pvzID.String()+"/receptions?endDate=yesterday", nil)
+	req.SetPathValue("pvzId", pvzID.String())
+	w := httptest.NewRecorder()
+	handler(w, req)
+
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestGetReceptionHandler_Success(t *testing.T) {
+	service := new(mockReceptionService)
+	handler := controller.GetReceptionHandler(service)
+
+	id := uuid.New()
+	service.On("GetReception", mock.Anything, id, employeePrincipal).Return(&domain.ReceptionWithProducts{
+		Reception: domain.Reception{ID: id},
+		Products:  []domain.Product{{ID: uuid.New(), Type: "обувь", ReceptionID: id}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/receptions/"+id.String(), nil)
+	req.SetPathValue("id", id.String())
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), employeePrincipal))
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp domain.ReceptionWithProducts
+	_ = json.NewDecoder(w.Body).Decode(&resp)
+	assert.Equal(t, id, resp.Reception.ID)
+	assert.Len(t, resp.Products, 1)
+}
+
+func TestGetReceptionHandler_NotFound(t *testing.T) {
+	service := new(mockReceptionService)
+	handler := controller.GetReceptionHandler(service)
+
+	id := uuid.New()
+	service.On("GetReception", mock.Anything, id, mock.Anything).Return(nil, svc.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/receptions/"+id.String(), nil)
+	req.SetPathValue("id", id.String())
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"pvs/internal/service"
+)
+
+type ListResponse[T any] struct {
+	Items      []T    `json:"items"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	codeBadRequest    = "bad_request"
+	codeInternalError = "internal_error"
+)
+
+var errorStatuses = map[string]int{
+	service.ErrInvalidInput.Code:         http.StatusUnprocessableEntity,
+	service.ErrForbidden.Code:            http.StatusForbidden,
+	service.ErrNotFound.Code:             http.StatusNotFound,
+	service.ErrNoOpenReception.Code:      http.StatusConflict,
+	service.ErrReceptionAlreadyOpen.Code: http.StatusConflict,
+	service.ErrCityNotAllowed.Code:       http.StatusUnprocessableEntity,
+	service.ErrCityAlreadyExists.Code:    http.StatusConflict,
+	service.ErrUserAlreadyExists.Code:    http.StatusConflict,
+	service.ErrInvalidCredentials.Code:   http.StatusUnauthorized,
+	service.ErrInvalidRefreshToken.Code:  http.StatusUnauthorized,
+	service.ErrWeakPassword.Code:         http.StatusUnprocessableEntity,
+	service.ErrTooManyLoginAttempts.Code: http.StatusTooManyRequests,
+
+	service.ErrUnknownProductType.Code:       http.StatusUnprocessableEntity,
+	service.ErrProductTypeAlreadyExists.Code: http.StatusConflict,
+	service.ErrProductTypeInUse.Code:         http.StatusConflict,
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("❌ encode response: %v", err)
+	}
+}
+
+func writeBadRequest(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusBadRequest, ErrorResponse{Code: codeBadRequest, Message: message})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var svcErr *service.Error
+	if errors.As(err, &svcErr) {
+		if status, ok := errorStatuses[svcErr.Code]; ok {
+			writeJSON(w, status, ErrorResponse{Code: svcErr.Code, Message: svcErr.Message})
+			return
+		}
+	}
+
+	log.Printf("❌ unhandled error: %v", err)
+	writeJSON(w, http.StatusInternalServerError, ErrorResponse{
+		Code:    codeInternalError,
+		Message: "внутренняя ошибка сервера",
+	})
+}
@@ -0,0 +1,59 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/controller"
+	svc "pvs/internal/service"
+)
+
+func TestErrorResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"forbidden", svc.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"not found", svc.ErrNotFound, http.StatusNotFound, "not_found"},
+		{"no open reception", svc.ErrNoOpenReception, http.StatusConflict, "no_open_reception"},
+		{"reception already open", svc.ErrReceptionAlreadyOpen, http.StatusConflict, "reception_already_open"},
+		{"city not allowed", svc.ErrCityNotAllowed, http.StatusUnprocessableEntity, "city_not_allowed"},
+		{"invalid input", svc.ErrInvalidInput, http.StatusUnprocessableEntity, "invalid_input"},
+		{"wrapped", fmt.Errorf("close: %w", svc.ErrForbidden), http.StatusForbidden, "forbidden"},
+		{"unknown", errors.New("pgx: connection reset"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(mockReceptionService)
+			handler := controller.CloseLastReceptionHandler(service)
+
+			pvzID := uuid.New()
+			service.On("CloseLastReception", mock.Anything, pvzID, employeePrincipal, "").Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/close_last_reception", nil)
+			req.SetPathValue("pvzId", pvzID.String())
+			req = req.WithContext(withRole(req.Context(), "employee"))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var resp controller.ErrorResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, tt.code, resp.Code)
+			assert.NotEmpty(t, resp.Message)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+type AssignStaffRequest struct {
+	UserID uuid.UUID `json:"userId"`
+}
+
+type StaffServiceInterface interface {
+	AssignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) (*domain.StaffAssignment, error)
+	UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) error
+	ListStaff(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) ([]domain.StaffAssignment, error)
+}
+
+func ListStaffHandler(s StaffServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pvzID, err := uuid.Parse(r.PathValue("pvzId"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		staff, err := s.ListStaff(r.Context(), pvzID, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, staff)
+	}
+}
+
+func AssignStaffHandler(s StaffServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pvzID, err := uuid.Parse(r.PathValue("pvzId"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+
+		var req AssignStaffRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+			writeBadRequest(w, "неверный формат запроса")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		assignment, err := s.AssignStaff(r.Context(), pvzID, req.UserID, principal)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, assignment)
+	}
+}
+
+func UnassignStaffHandler(s StaffServiceInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pvzID, err := uuid.Parse(r.PathValue("pvzId"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+		userID, err := uuid.Parse(r.PathValue("userId"))
+		if err != nil {
+			writeBadRequest(w, "неверный UUID")
+			return
+		}
+
+		principal, _ := middleware.PrincipalFromContext(r.Context())
+		if err := s.UnassignStaff(r.Context(), pvzID, userID, principal); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
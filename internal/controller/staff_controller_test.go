@@ -0,0 +1,87 @@
+package controller_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/controller"
+	"pvs/internal/domain"
+	svc "pvs/internal/service"
+	"pvs/internal/transport/middleware"
+)
+
+type mockStaffService struct {
+	mock.Mock
+}
+
+func (m *mockStaffService) AssignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) (*domain.StaffAssignment, error) {
+	args := m.Called(ctx, pvzID, userID, principal)
+	if a := args.Get(0); a != nil {
+		return a.(*domain.StaffAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStaffService) UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) error {
+	args := m.Called(ctx, pvzID, userID, principal)
+	return args.Error(0)
+}
+
+func (m *mockStaffService) ListStaff(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) ([]domain.StaffAssignment, error) {
+	args := m.Called(ctx, pvzID, principal)
+	return args.Get(0).([]domain.StaffAssignment), args.Error(1)
+}
+
+var moderatorPrincipal = domain.Principal{Role: "moderator"}
+
+func TestAssignStaffHandler_Success(t *testing.T) {
+	service := new(mockStaffService)
+	pvzID, userID := uuid.New(), uuid.New()
+	service.On("AssignStaff", mock.Anything, pvzID, userID, moderatorPrincipal).
+		Return(&domain.StaffAssignment{PVZID: pvzID, UserID: userID}, nil)
+
+	body, _ := json.Marshal(map[string]string{"userId": userID.String()})
+	req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/staff", bytes.NewReader(body))
+	req.SetPathValue("pvzId", pvzID.String())
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), moderatorPrincipal))
+	w := httptest.NewRecorder()
+	controller.AssignStaffHandler(service)(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestAssignStaffHandler_MissingUserID(t *testing.T) {
+	service := new(mockStaffService)
+	pvzID := uuid.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz/"+pvzID.String()+"/staff", bytes.NewReader([]byte(`{}`)))
+	req.SetPathValue("pvzId", pvzID.String())
+	w := httptest.NewRecorder()
+	controller.AssignStaffHandler(service)(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	service.AssertNotCalled(t, "AssignStaff", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUnassignStaffHandler_NotAssigned(t *testing.T) {
+	service := new(mockStaffService)
+	pvzID, userID := uuid.New(), uuid.New()
+	service.On("UnassignStaff", mock.Anything, pvzID, userID, moderatorPrincipal).Return(svc.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodDelete, "/pvz/"+pvzID.String()+"/staff/"+userID.String(), nil)
+	req.SetPathValue("pvzId", pvzID.String())
+	req.SetPathValue("userId", userID.String())
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), moderatorPrincipal))
+	w := httptest.NewRecorder()
+	controller.UnassignStaffHandler(service)(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+type City struct {
+	Name      string
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
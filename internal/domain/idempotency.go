@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type IdempotencyRecord struct {
+	Key          string
+	Principal    string
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	ExpiresAt    time.Time
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+type LoginAttempt struct {
+	Key         string
+	Failures    int
+	LockedUntil *time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Principal struct {
+	UserID    uuid.UUID
+	Email     string
+	Role      string
+	TokenID   string
+	ExpiresAt time.Time
+	Dummy     bool
+}
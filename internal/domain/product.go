@@ -11,4 +11,10 @@ type Product struct {
 	Type        string
 	ReceptionID uuid.UUID
 	DateTime    time.Time
+	CreatedBy   *uuid.UUID
+}
+
+type ProductType struct {
+	Name      string
+	CreatedAt time.Time
 }
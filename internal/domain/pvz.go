@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,3 +14,52 @@ type PVZ struct {
 	City             string
 	RegistrationDate time.Time
 }
+
+type PVZWithReceptions struct {
+	PVZ        PVZ                     `json:"pvz"`
+	Receptions []ReceptionWithProducts `json:"receptions"`
+}
+
+type PVZFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Page      int
+	Limit     int
+	After     *PVZCursor
+}
+
+// Page is one page of a filtered listing.
+type Page[T any] struct {
+	Items      []T
+	Total      int
+	HasMore    bool
+	NextCursor string
+}
+
+type PVZPage = Page[PVZWithReceptions]
+
+// PVZCursor points at the last PVZ of a page in (registration_date, id) order.
+// Clients only ever see its opaque encoded form.
+type PVZCursor struct {
+	RegistrationDate time.Time `json:"d"`
+	ID               uuid.UUID `json:"i"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+func (c PVZCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodePVZCursor(raw string) (*PVZCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c PVZCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.RegistrationDate.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
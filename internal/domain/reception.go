@@ -7,8 +7,34 @@ import (
 )
 
 type Reception struct {
-	ID       uuid.UUID
-	PVZID    uuid.UUID
-	DateTime time.Time
-	Status   string
+	ID        uuid.UUID
+	PVZID     uuid.UUID
+	DateTime  time.Time
+	Status    string
+	CreatedBy *uuid.UUID
+	ClosedBy  *uuid.UUID
+}
+
+const (
+	ReceptionInProgress = "in_progress"
+	ReceptionClosed     = "close"
+)
+
+type ReceptionFilter struct {
+	Status    string
+	StartDate *time.Time
+	EndDate   *time.Time
+	Page      int
+	Limit     int
+}
+
+type ReceptionPage struct {
+	Items   []Reception
+	Total   int
+	HasMore bool
+}
+
+type ReceptionWithProducts struct {
+	Reception Reception `json:"reception"`
+	Products  []Product `json:"products"`
 }
@@ -0,0 +1,14 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StaffAssignment struct {
+	PVZID      uuid.UUID
+	UserID     uuid.UUID
+	AssignedBy *uuid.UUID
+	AssignedAt time.Time
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type CheckFunc func(ctx context.Context) error
+
+// Failure reasons reported to callers. The underlying error can name hosts,
+// users or databases, so it is only logged.
+const (
+	ErrorTimeout = "timeout"
+	ErrorFailed  = "check failed"
+)
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs named readiness checks concurrently, each bounded by timeout.
+type Checker struct {
+	timeout time.Duration
+	checks  map[string]CheckFunc
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, checks: make(map[string]CheckFunc)}
+}
+
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.checks[name] = check
+}
+
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := CheckResult{Status: StatusOK}
+			if err := check(ctx); err != nil {
+				log.Printf("❌ readiness check %s failed: %v", name, err)
+				result = CheckResult{Status: StatusFail, Error: ErrorFailed}
+				if errors.Is(err, context.DeadlineExceeded) {
+					result.Error = ErrorTimeout
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// LivenessHandler only reports that the process serves HTTP; it never
+// touches dependencies, so a database outage does not get the pod restarted.
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusOK})
+	}
+}
+
+func (c *Checker) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, report)
+	}
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("❌ encode health report: %v", err)
+	}
+}
+
+// LatestMigration returns the highest goose version among the .sql files in
+// fsys, which is the version a fully migrated database must report.
+func LatestMigration(fsys fs.FS) (int64, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
+
+// MigrationCheck fails until the database schema version equals the latest
+// migration shipped with the binary.
+func MigrationCheck(current func(ctx context.Context) (int64, error), migrations fs.FS) CheckFunc {
+	return func(ctx context.Context) error {
+		want, err := LatestMigration(migrations)
+		if err != nil {
+			return err
+		}
+		got, err := current(ctx)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("schema version %d, expected %d", got, want)
+		}
+		return nil
+	}
+}
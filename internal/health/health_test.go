@@ -0,0 +1,95 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/health"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestReadinessHandler_AllChecksPass(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", ok)
+	checker.Add("migrations", ok)
+
+	w := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report health.Report
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusOK}, report.Checks["postgres"])
+	assert.Equal(t, health.CheckResult{Status: health.StatusOK}, report.Checks["migrations"])
+}
+
+func TestReadinessHandler_FailingCheck(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return errors.New("connection refused") })
+	checker.Add("migrations", ok)
+
+	w := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var report health.Report
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.CheckResult{Status: health.StatusFail, Error: health.ErrorFailed}, report.Checks["postgres"])
+	assert.NotContains(t, w.Body.String(), "connection refused")
+	assert.Equal(t, health.StatusOK, report.Checks["migrations"].Status)
+}
+
+func TestRun_CheckTimeout(t *testing.T) {
+	checker := health.NewChecker(20 * time.Millisecond)
+	checker.Add("postgres", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := checker.Run(context.Background())
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.ErrorTimeout, report.Checks["postgres"].Error)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	health.LivenessHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+var migrations = fstest.MapFS{
+	"001_init.sql":          {},
+	"002_reception.sql":     {},
+	"010_pvz_staff.sql":     {},
+	"README.md":             {},
+	"embed.go":              {},
+	"notes_without_version": {},
+}
+
+func TestLatestMigration(t *testing.T) {
+	latest, err := health.LatestMigration(migrations)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), latest)
+}
+
+func TestMigrationCheck(t *testing.T) {
+	version := func(v int64) func(context.Context) (int64, error) {
+		return func(context.Context) (int64, error) { return v, nil }
+	}
+
+	assert.NoError(t, health.MigrationCheck(version(10), migrations)(context.Background()))
+	assert.EqualError(t, health.MigrationCheck(version(2), migrations)(context.Background()), "schema version 2, expected 10")
+}
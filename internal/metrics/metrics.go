@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Количество HTTP-запросов по маршруту и статусу",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Время обработки HTTP-запросов по маршруту и статусу",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	PVZCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pvz_created_total",
+		Help: "Количество созданных ПВЗ",
+	})
+
+	ReceptionsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "receptions_created_total",
+		Help: "Количество открытых приёмок",
+	})
+
+	ReceptionsClosedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "receptions_closed_total",
+		Help: "Количество закрытых приёмок",
+	})
+
+	ProductsAddedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "products_added_total",
+		Help: "Количество добавленных товаров",
+	})
+)
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,126 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"pvs/migrations"
+)
+
+// Migrator applies the embedded migrations. Up and Down take a Postgres
+// advisory lock for their duration, so replicas that start together apply
+// each migration exactly once while the rest wait.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func Open(databaseURL string) (*Migrator, error) {
+	cfg, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	// migrations report data they had to change with RAISE NOTICE
+	cfg.OnNotice = func(_ *pgconn.PgConn, n *pgconn.Notice) {
+		log.Printf("📦 %s", n.Message)
+	}
+	db := stdlib.OpenDB(*cfg)
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migration lock: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("goose.NewProvider: %w", err)
+	}
+	return &Migrator{provider: provider}, nil
+}
+
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	for _, r := range results {
+		log.Printf("📦 applied %s in %s", r.Source.Path, r.Duration)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate up: %w", err)
+	}
+	if len(results) == 0 {
+		log.Println("📦 no pending migrations")
+	}
+	return nil
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate down: %w", err)
+	}
+	log.Printf("📦 rolled back %s in %s", result.Source.Path, result.Duration)
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	return m.provider.GetDBVersion(ctx)
+}
+
+const migrationTemplate = `-- +goose Up
+
+-- +goose Down
+`
+
+// Create writes a new blank SQL migration into dir, numbered after the
+// existing ones, and returns its path. Run it from the source tree: the file
+// is only picked up by a binary built afterwards.
+func Create(dir, name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if name == "" {
+		return "", errors.New("migration name must not be empty")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var next int64 = 1
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		if version, err := goose.NumericComponent(entry.Name()); err == nil {
+			next = max(next, version+1)
+		}
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%03d_%s.sql", next, name))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(migrationTemplate); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,55 @@
+package migrate_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/migrate"
+	"pvs/migrations"
+)
+
+func TestEmbeddedMigrationsMatchSourceTree(t *testing.T) {
+	embedded, err := fs.Glob(migrations.FS, "*.sql")
+	require.NoError(t, err)
+	onDisk, err := filepath.Glob("../../migrations/*.sql")
+	require.NoError(t, err)
+
+	require.NotEmpty(t, embedded)
+	require.Len(t, embedded, len(onDisk))
+	for i, path := range onDisk {
+		assert.Equal(t, filepath.Base(path), embedded[i])
+	}
+}
+
+func TestCreate_NumbersAfterExisting(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "001_init.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "009_login_attempts.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o644))
+
+	path, err := migrate.Create(dir, "Add Pickup Slots")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "010_add_pickup_slots.sql"), path)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "-- +goose Up")
+	assert.Contains(t, string(content), "-- +goose Down")
+}
+
+func TestCreate_EmptyDir(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := migrate.Create(dir, "init")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "001_init.sql"), path)
+}
+
+func TestCreate_EmptyName(t *testing.T) {
+	_, err := migrate.Create(t.TempDir(), "  ")
+	assert.Error(t, err)
+}
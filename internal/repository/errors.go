@@ -0,0 +1,10 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrAlreadyExists   = errors.New("already exists")
+	ErrInUse           = errors.New("in use")
+	ErrBrokenReference = errors.New("broken reference")
+)
@@ -8,24 +8,100 @@ import (
 	"pvs/internal/domain"
 )
 
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
+	// EnsureUser inserts user with its own ID unless the ID or email is taken.
+	EnsureUser(ctx context.Context, user domain.User) error
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	// ListUsers and SetRole leave Password empty: admin listings never
+	// need the hash.
+	ListUsers(ctx context.Context) ([]domain.User, error)
+	SetRole(ctx context.Context, id uuid.UUID, role string) (*domain.User, error)
+}
+
+type LoginAttemptRepository interface {
+	GetLoginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempt, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginAttempts(ctx context.Context, key string) error
+	// DeleteStale removes rows not updated within window whose lockout has ended.
+	DeleteStale(ctx context.Context, window time.Duration) error
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	MarkRotated(ctx context.Context, id, replacedBy uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	DeleteExpiredRefreshTokens(ctx context.Context) error
+}
+
+type RevokedTokenRepository interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevocations(ctx context.Context) error
 }
 
 type PVZRepository interface {
 	CreatePVZ(ctx context.Context, city string) (*domain.PVZ, error)
-	ListPVZWithFilter(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]domain.PVZ, error)
+	GetPVZ(ctx context.Context, id uuid.UUID) (*domain.PVZ, error)
+	LockPVZ(ctx context.Context, id uuid.UUID) error
+	// ListPVZWithFilter returns up to filter.Limit+1 rows so that callers can
+	// tell whether another page exists.
+	ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) ([]domain.PVZ, error)
+	CountPVZ(ctx context.Context, filter domain.PVZFilter) (int, error)
 }
 
 type ReceptionRepository interface {
-	CreateReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error)
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error)
+	CreateReception(ctx context.Context, pvzID, createdBy uuid.UUID) (*domain.Reception, error)
+	CloseLastReception(ctx context.Context, pvzID, closedBy uuid.UUID, idempotencyKey string) (*domain.Reception, error)
+	// GetClosedByIdempotencyKey finds a reception closedBy closed with the key.
+	// Keys of other users on the same PVZ never match.
+	GetClosedByIdempotencyKey(ctx context.Context, pvzID, closedBy uuid.UUID, idempotencyKey string) (*domain.Reception, error)
 	GetOpenReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error)
+	GetReception(ctx context.Context, id uuid.UUID) (*domain.Reception, error)
+	// ListReceptions returns up to filter.Limit+1 rows, like ListPVZWithFilter.
+	ListReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter) ([]domain.Reception, error)
+	CountReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter) (int, error)
+	ListByPVZIDs(ctx context.Context, pvzIDs []uuid.UUID, startDate, endDate *time.Time) ([]domain.Reception, error)
+}
+
+type StaffRepository interface {
+	AssignStaff(ctx context.Context, pvzID, userID, assignedBy uuid.UUID) (*domain.StaffAssignment, error)
+	UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID) error
+	ListStaff(ctx context.Context, pvzID uuid.UUID) ([]domain.StaffAssignment, error)
+	IsAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error)
 }
 
 type ProductRepository interface {
-	AddProduct(ctx context.Context, receptionID uuid.UUID, productType string) (*domain.Product, error)
+	AddProduct(ctx context.Context, receptionID uuid.UUID, productType string, createdBy uuid.UUID) (*domain.Product, error)
 	DeleteLastProduct(ctx context.Context, receptionID uuid.UUID) error
 	GetProductsByReception(ctx context.Context, receptionID uuid.UUID) ([]domain.Product, error)
+	ListByReceptionIDs(ctx context.Context, receptionIDs []uuid.UUID) ([]domain.Product, error)
+}
+
+type ProductTypeRepository interface {
+	ListProductTypes(ctx context.Context) ([]domain.ProductType, error)
+	CreateProductType(ctx context.Context, name string) (*domain.ProductType, error)
+	RenameProductType(ctx context.Context, name, newName string) (*domain.ProductType, error)
+	DeleteProductType(ctx context.Context, name string) error
+	ProductTypeExists(ctx context.Context, name string) (bool, error)
+}
+
+type CityRepository interface {
+	ListCities(ctx context.Context) ([]domain.City, error)
+	CreateCity(ctx context.Context, name string, enabled bool) (*domain.City, error)
+	SetCityEnabled(ctx context.Context, name string, enabled bool) (*domain.City, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, key, principal string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, key, principal string) error
+	DeleteExpired(ctx context.Context) error
 }
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+type PostgresCityRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCityRepository(pool *pgxpool.Pool) *PostgresCityRepository {
+	return &PostgresCityRepository{pool: pool}
+}
+
+func (r *PostgresCityRepository) ListCities(ctx context.Context) ([]domain.City, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT name, enabled, created_at, updated_at FROM city ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cities []domain.City
+	for rows.Next() {
+		var c domain.City
+		if err := rows.Scan(&c.Name, &c.Enabled, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		cities = append(cities, c)
+	}
+	return cities, rows.Err()
+}
+
+func (r *PostgresCityRepository) CreateCity(ctx context.Context, name string, enabled bool) (*domain.City, error) {
+	var c domain.City
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO city (name, enabled) VALUES ($1, $2)
+		RETURNING name, enabled, created_at, updated_at
+	`, name, enabled).Scan(&c.Name, &c.Enabled, &c.CreatedAt, &c.UpdatedAt)
+	if isUniqueViolation(err) {
+		return nil, repository.ErrAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresCityRepository) SetCityEnabled(ctx context.Context, name string, enabled bool) (*domain.City, error) {
+	var c domain.City
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE city SET enabled = $2, updated_at = now()
+		WHERE name = $1
+		RETURNING name, enabled, created_at, updated_at
+	`, name, enabled).Scan(&c.Name, &c.Enabled, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+)
+
+type PostgresIdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{pool: pool}
+}
+
+func (r *PostgresIdempotencyRepository) Reserve(
+	ctx context.Context,
+	rec *domain.IdempotencyRecord,
+) (*domain.IdempotencyRecord, bool, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO idempotency_key (key, principal, method, path, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (key, principal) DO UPDATE
+		SET method = EXCLUDED.method,
+		    path = EXCLUDED.path,
+		    request_hash = EXCLUDED.request_hash,
+		    status_code = NULL,
+		    content_type = '',
+		    response_body = NULL,
+		    created_at = now(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_key.expires_at < now()
+	`, rec.Key, rec.Principal, rec.Method, rec.Path, rec.RequestHash, rec.ExpiresAt)
+	if err != nil {
+		return nil, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return rec, true, nil
+	}
+
+	var existing domain.IdempotencyRecord
+	var status *int
+	err = conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT key, principal, method, path, request_hash, status_code, content_type, response_body, expires_at
+		FROM idempotency_key
+		WHERE key = $1 AND principal = $2
+	`, rec.Key, rec.Principal).Scan(
+		&existing.Key, &existing.Principal, &existing.Method, &existing.Path, &existing.RequestHash,
+		&status, &existing.ContentType, &existing.ResponseBody, &existing.ExpiresAt,
+	)
+	if err != nil {
+		return nil, false, mapNoRows(err)
+	}
+	if status != nil {
+		existing.StatusCode = *status
+	}
+	return &existing, false, nil
+}
+
+func (r *PostgresIdempotencyRepository) Complete(
+	ctx context.Context,
+	key, principal string,
+	statusCode int,
+	contentType string,
+	body []byte,
+) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE idempotency_key
+		SET status_code = $3, content_type = $4, response_body = $5
+		WHERE key = $1 AND principal = $2
+	`, key, principal, statusCode, contentType, body)
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) Release(ctx context.Context, key, principal string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM idempotency_key WHERE key = $1 AND principal = $2 AND status_code IS NULL
+	`, key, principal)
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) DeleteExpired(ctx context.Context) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM idempotency_key WHERE expires_at < now()
+	`)
+	return err
+}
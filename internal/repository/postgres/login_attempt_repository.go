@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+)
+
+type PostgresLoginAttemptRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(pool *pgxpool.Pool) *PostgresLoginAttemptRepository {
+	return &PostgresLoginAttemptRepository{pool: pool}
+}
+
+func (r *PostgresLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*domain.LoginAttempt, error) {
+	var a domain.LoginAttempt
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT key, failures, locked_until, updated_at FROM login_attempt WHERE key = $1
+	`, key).Scan(&a.Key, &a.Failures, &a.LockedUntil, &a.UpdatedAt)
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &a, nil
+}
+
+func (r *PostgresLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*domain.LoginAttempt, error) {
+	var a domain.LoginAttempt
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO login_attempt (key, failures, updated_at) VALUES ($1, 1, now())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempt.updated_at < now() - make_interval(secs => $2) THEN 1
+				ELSE login_attempt.failures + 1
+			END,
+			updated_at = now()
+		RETURNING key, failures, locked_until, updated_at
+	`, key, window.Seconds()).Scan(&a.Key, &a.Failures, &a.LockedUntil, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PostgresLoginAttemptRepository) LockLogin(ctx context.Context, key string, until time.Time) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE login_attempt SET locked_until = $2 WHERE key = $1
+	`, key, until)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM login_attempt WHERE key = $1`, key)
+	return err
+}
+
+func (r *PostgresLoginAttemptRepository) DeleteStale(ctx context.Context, window time.Duration) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM login_attempt
+		WHERE updated_at < now() - make_interval(secs => $1)
+			AND (locked_until IS NULL OR locked_until < now())
+	`, window.Seconds())
+	return err
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresMigrationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMigrationRepository(pool *pgxpool.Pool) *PostgresMigrationRepository {
+	return &PostgresMigrationRepository{pool: pool}
+}
+
+// Version returns the goose schema version, 0 if nothing has been applied.
+// A version rolled back by a later down migration does not count.
+func (r *PostgresMigrationRepository) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT COALESCE(MAX(version_id), 0) FROM (
+			SELECT DISTINCT ON (version_id) version_id, is_applied
+			FROM goose_db_version
+			ORDER BY version_id, id DESC
+		) latest
+		WHERE is_applied
+	`).Scan(&version)
+	if isUndefinedTable(err) {
+		return 0, nil
+	}
+	return version, err
+}
@@ -0,0 +1,10 @@
+package postgres
+
+import "github.com/google/uuid"
+
+func nullUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+type PostgresProductTypeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProductTypeRepository(pool *pgxpool.Pool) *PostgresProductTypeRepository {
+	return &PostgresProductTypeRepository{pool: pool}
+}
+
+func (r *PostgresProductTypeRepository) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT name, created_at FROM product_type ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []domain.ProductType
+	for rows.Next() {
+		var pt domain.ProductType
+		if err := rows.Scan(&pt.Name, &pt.CreatedAt); err != nil {
+			return nil, err
+		}
+		types = append(types, pt)
+	}
+	return types, rows.Err()
+}
+
+func (r *PostgresProductTypeRepository) CreateProductType(ctx context.Context, name string) (*domain.ProductType, error) {
+	var pt domain.ProductType
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO product_type (name) VALUES ($1)
+		RETURNING name, created_at
+	`, name).Scan(&pt.Name, &pt.CreatedAt)
+	if isUniqueViolation(err) {
+		return nil, repository.ErrAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pt, nil
+}
+
+// RenameProductType renames a type; products of that type follow through
+// ON UPDATE CASCADE on product_type_fkey.
+func (r *PostgresProductTypeRepository) RenameProductType(ctx context.Context, name, newName string) (*domain.ProductType, error) {
+	var pt domain.ProductType
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		UPDATE product_type SET name = $2 WHERE name = $1
+		RETURNING name, created_at
+	`, name, newName).Scan(&pt.Name, &pt.CreatedAt)
+	if isUniqueViolation(err) {
+		return nil, repository.ErrAlreadyExists
+	}
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &pt, nil
+}
+
+func (r *PostgresProductTypeRepository) DeleteProductType(ctx context.Context, name string) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM product_type WHERE name = $1`, name)
+	if isForeignKeyViolation(err) {
+		return repository.ErrInUse
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresProductTypeRepository) ProductTypeExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM product_type WHERE name = $1)
+	`, name).Scan(&exists)
+	return exists, err
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"pvs/internal/domain"
 )
@@ -18,7 +19,7 @@ func NewPVSRepository(pool *pgxpool.Pool) *PostgresPVZRepository {
 }
 
 func (r *PostgresPVZRepository) CreatePVZ(ctx context.Context, city string) (*domain.PVZ, error) {
-	row := r.pool.QueryRow(ctx, `
+	row := conn(ctx, r.pool).QueryRow(ctx, `
 		INSERT INTO pvz (id, city) VALUES (gen_random_uuid(), $1)
 		RETURNING id, city, registration_date
 	`, city)
@@ -28,26 +29,57 @@ func (r *PostgresPVZRepository) CreatePVZ(ctx context.Context, city string) (*do
 	return &pvz, err
 }
 
-func (r *PostgresPVZRepository) ListPVZWithFilter(
-	ctx context.Context,
-	startDate, endDate *time.Time,
-	page, limit int,
-) ([]domain.PVZ, error) {
+func (r *PostgresPVZRepository) GetPVZ(ctx context.Context, id uuid.UUID) (*domain.PVZ, error) {
+	var pvz domain.PVZ
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT id, city, registration_date FROM pvz WHERE id = $1
+	`, id).Scan(&pvz.ID, &pvz.City, &pvz.RegistrationDate)
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &pvz, nil
+}
+
+func (r *PostgresPVZRepository) LockPVZ(ctx context.Context, id uuid.UUID) error {
+	var locked uuid.UUID
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT id FROM pvz WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	return mapNoRows(err)
+}
+
+const pvzReceptionFilter = `(($1::timestamp IS NULL AND $2::timestamp IS NULL) OR EXISTS (
+			SELECT 1 FROM reception r
+			WHERE r.pvz_id = pvz.id
+			  AND ($1::timestamp IS NULL OR r.date_time >= $1::timestamp)
+			  AND ($2::timestamp IS NULL OR r.date_time <= $2::timestamp)
+		))`
+
+// ListPVZWithFilter pages by keyset when filter.After is set and falls back to
+// LIMIT/OFFSET otherwise. Both modes share the (registration_date, id) order.
+func (r *PostgresPVZRepository) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) ([]domain.PVZ, error) {
 	query := `
-		SELECT DISTINCT pvz.id, pvz.city, pvz.registration_date
+		SELECT pvz.id, pvz.city, pvz.registration_date
 		FROM pvz
-		LEFT JOIN reception r ON r.pvz_id = pvz.id
-		WHERE ($1::timestamp IS NULL OR r.date_time >= $1::timestamp)
-		  AND ($2::timestamp IS NULL OR r.date_time <= $2::timestamp)
-		ORDER BY pvz.registration_date DESC
-		LIMIT $3 OFFSET $4
+		WHERE ` + pvzReceptionFilter + `
+		  AND ($3::timestamp IS NULL OR (pvz.registration_date, pvz.id) < ($3::timestamp, $4::uuid))
+		ORDER BY pvz.registration_date DESC, pvz.id DESC
+		LIMIT $5 OFFSET $6
 	`
 
-	offset := (page - 1) * limit
+	var (
+		afterDate *time.Time
+		afterID   *uuid.UUID
+		offset    int
+	)
+	if filter.After != nil {
+		afterDate, afterID = &filter.After.RegistrationDate, &filter.After.ID
+	} else {
+		offset = (filter.Page - 1) * filter.Limit
+	}
 
-	log.Printf("📦 ListPVZWithFilter called with: startDate=%v, endDate=%v, page=%d, limit=%d", startDate, endDate, page, limit)
+	log.Printf("📦 ListPVZWithFilter called with: startDate=%v, endDate=%v, page=%d, limit=%d, cursor=%t",
+		filter.StartDate, filter.EndDate, filter.Page, filter.Limit, filter.After != nil)
 
-	rows, err := r.pool.Query(ctx, query, startDate, endDate, limit, offset)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, filter.StartDate, filter.EndDate, afterDate, afterID, filter.Limit+1, offset)
 	if err != nil {
 		log.Printf("❌ query error: %v", err)
 		return nil, err
@@ -65,5 +97,14 @@ func (r *PostgresPVZRepository) ListPVZWithFilter(
 	}
 
 	log.Printf("✅ ListPVZWithFilter result count: %d", len(result))
-	return result, nil
+	return result, rows.Err()
+}
+
+func (r *PostgresPVZRepository) CountPVZ(ctx context.Context, filter domain.PVZFilter) (int, error) {
+	var total int
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT count(*) FROM pvz WHERE `+pvzReceptionFilter,
+		filter.StartDate, filter.EndDate,
+	).Scan(&total)
+	return total, err
 }
@@ -2,12 +2,17 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 )
 
+const receptionColumns = `id, pvz_id, date_time, status, created_by, closed_by`
+
 type PostgresReceptionRepository struct {
 	pool *pgxpool.Pool
 }
@@ -16,38 +21,150 @@ func NewReceptionRepository(pool *pgxpool.Pool) *PostgresReceptionRepository {
 	return &PostgresReceptionRepository{pool: pool}
 }
 
-func (r *PostgresReceptionRepository) CreateReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error) {
+func scanReception(row pgx.Row) (*domain.Reception, error) {
 	var rec domain.Reception
-	err := r.pool.QueryRow(ctx,
-		`INSERT INTO reception (id, pvz_id, status) VALUES (gen_random_uuid(), $1, 'in_progress')
-		 RETURNING id, pvz_id, date_time, status`,
-		pvzID,
-	).Scan(&rec.ID, &rec.PVZID, &rec.DateTime, &rec.Status)
-	return &rec, err
+	err := row.Scan(&rec.ID, &rec.PVZID, &rec.DateTime, &rec.Status, &rec.CreatedBy, &rec.ClosedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *PostgresReceptionRepository) CreateReception(ctx context.Context, pvzID, createdBy uuid.UUID) (*domain.Reception, error) {
+	rec, err := scanReception(conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO reception (id, pvz_id, status, created_by) VALUES (gen_random_uuid(), $1, 'in_progress', $2)
+		RETURNING `+receptionColumns,
+		pvzID, nullUUID(createdBy),
+	))
+	if isUniqueViolation(err) {
+		return nil, repository.ErrAlreadyExists
+	}
+	return rec, err
 }
 
 func (r *PostgresReceptionRepository) GetOpenReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error) {
-	var rec domain.Reception
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, pvz_id, date_time, status FROM reception
+	rec, err := scanReception(conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT `+receptionColumns+` FROM reception
 		WHERE pvz_id = $1 AND status = 'in_progress'
 		ORDER BY date_time DESC LIMIT 1
-	`, pvzID).Scan(&rec.ID, &rec.PVZID, &rec.DateTime, &rec.Status)
-	return &rec, err
+	`, pvzID))
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return rec, nil
 }
 
-func (r *PostgresReceptionRepository) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error) {
-	var rec domain.Reception
-	err := r.pool.QueryRow(ctx, `
+func (r *PostgresReceptionRepository) CloseLastReception(
+	ctx context.Context,
+	pvzID, closedBy uuid.UUID,
+	idempotencyKey string,
+) (*domain.Reception, error) {
+	rec, err := scanReception(conn(ctx, r.pool).QueryRow(ctx, `
 		UPDATE reception
-		SET status = 'close'
+		SET status = 'close', close_idempotency_key = NULLIF($2, ''), closed_by = $3
 		WHERE id = (
 			SELECT id FROM reception
 			WHERE pvz_id = $1 AND status = 'in_progress'
 			ORDER BY date_time DESC
 			LIMIT 1
 		)
-		RETURNING id, pvz_id, date_time, status
-	`, pvzID).Scan(&rec.ID, &rec.PVZID, &rec.DateTime, &rec.Status)
-	return &rec, err
+		RETURNING `+receptionColumns,
+		pvzID, idempotencyKey, nullUUID(closedBy),
+	))
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return rec, nil
+}
+
+func (r *PostgresReceptionRepository) GetReception(ctx context.Context, id uuid.UUID) (*domain.Reception, error) {
+	rec, err := scanReception(conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT `+receptionColumns+` FROM reception WHERE id = $1
+	`, id))
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return rec, nil
+}
+
+const receptionFilter = `
+	WHERE pvz_id = $1
+	  AND ($2::text = '' OR status = $2::text)
+	  AND ($3::timestamp IS NULL OR date_time >= $3::timestamp)
+	  AND ($4::timestamp IS NULL OR date_time <= $4::timestamp)
+`
+
+func (r *PostgresReceptionRepository) ListReceptions(
+	ctx context.Context,
+	pvzID uuid.UUID,
+	filter domain.ReceptionFilter,
+) ([]domain.Reception, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+receptionColumns+` FROM reception`+receptionFilter+`
+		ORDER BY date_time DESC, id DESC
+		LIMIT $5 OFFSET $6
+	`, pvzID, filter.Status, filter.StartDate, filter.EndDate, filter.Limit+1, (filter.Page-1)*filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receptions []domain.Reception
+	for rows.Next() {
+		rec, err := scanReception(rows)
+		if err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, *rec)
+	}
+	return receptions, rows.Err()
+}
+
+func (r *PostgresReceptionRepository) CountReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter) (int, error) {
+	var total int
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT count(*) FROM reception`+receptionFilter,
+		pvzID, filter.Status, filter.StartDate, filter.EndDate,
+	).Scan(&total)
+	return total, err
+}
+
+func (r *PostgresReceptionRepository) GetClosedByIdempotencyKey(ctx context.Context, pvzID, closedBy uuid.UUID, idempotencyKey string) (*domain.Reception, error) {
+	rec, err := scanReception(conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT `+receptionColumns+` FROM reception
+		WHERE pvz_id = $1 AND close_idempotency_key = $2 AND status = 'close'
+			AND closed_by IS NOT DISTINCT FROM $3
+	`, pvzID, idempotencyKey, nullUUID(closedBy)))
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return rec, nil
+}
+
+func (r *PostgresReceptionRepository) ListByPVZIDs(
+	ctx context.Context,
+	pvzIDs []uuid.UUID,
+	startDate, endDate *time.Time,
+) ([]domain.Reception, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT `+receptionColumns+` FROM reception
+		WHERE pvz_id = ANY($1::uuid[])
+		  AND ($2::timestamp IS NULL OR date_time >= $2::timestamp)
+		  AND ($3::timestamp IS NULL OR date_time <= $3::timestamp)
+		ORDER BY date_time ASC
+	`, pvzIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receptions []domain.Reception
+	for rows.Next() {
+		rec, err := scanReception(rows)
+		if err != nil {
+			return nil, err
+		}
+		receptions = append(receptions, *rec)
+	}
+	return receptions, rows.Err()
 }
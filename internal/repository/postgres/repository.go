@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 )
 
 type PostgresProductRepository struct {
@@ -15,18 +16,29 @@ func NewProductRepository(pool *pgxpool.Pool) *PostgresProductRepository {
 	return &PostgresProductRepository{pool: pool}
 }
 
-func (r *PostgresProductRepository) AddProduct(ctx context.Context, receptionID uuid.UUID, productType string) (*domain.Product, error) {
+func (r *PostgresProductRepository) AddProduct(
+	ctx context.Context,
+	receptionID uuid.UUID,
+	productType string,
+	createdBy uuid.UUID,
+) (*domain.Product, error) {
 	var p domain.Product
-	err := r.pool.QueryRow(ctx, `
-		INSERT INTO product (id, type, reception_id)
-		VALUES (gen_random_uuid(), $1, $2)
-		RETURNING id, type, reception_id, date_time
-	`, productType, receptionID).Scan(&p.ID, &p.Type, &p.ReceptionID, &p.DateTime)
-	return &p, err
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO product (id, type, reception_id, created_by)
+		VALUES (gen_random_uuid(), $1, $2, $3)
+		RETURNING id, type, reception_id, date_time, created_by
+	`, productType, receptionID, nullUUID(createdBy)).Scan(&p.ID, &p.Type, &p.ReceptionID, &p.DateTime, &p.CreatedBy)
+	if isForeignKeyViolation(err) {
+		return nil, repository.ErrBrokenReference
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
 func (r *PostgresProductRepository) DeleteLastProduct(ctx context.Context, receptionID uuid.UUID) error {
-	_, err := r.pool.Exec(ctx, `
+	_, err := conn(ctx, r.pool).Exec(ctx, `
 		DELETE FROM product
 		WHERE id = (
 			SELECT id FROM product
@@ -38,8 +50,8 @@ func (r *PostgresProductRepository) DeleteLastProduct(ctx context.Context, recep
 }
 
 func (r *PostgresProductRepository) GetProductsByReception(ctx context.Context, receptionID uuid.UUID) ([]domain.Product, error) {
-	rows, err := r.pool.Query(ctx, `
-		SELECT id, type, reception_id, date_time FROM product
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT id, type, reception_id, date_time, created_by FROM product
 		WHERE reception_id = $1
 		ORDER BY date_time ASC
 	`, receptionID)
@@ -51,10 +63,32 @@ func (r *PostgresProductRepository) GetProductsByReception(ctx context.Context,
 	var products []domain.Product
 	for rows.Next() {
 		var p domain.Product
-		if err := rows.Scan(&p.ID, &p.Type, &p.ReceptionID, &p.DateTime); err != nil {
+		if err := rows.Scan(&p.ID, &p.Type, &p.ReceptionID, &p.DateTime, &p.CreatedBy); err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (r *PostgresProductRepository) ListByReceptionIDs(ctx context.Context, receptionIDs []uuid.UUID) ([]domain.Product, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT id, type, reception_id, date_time, created_by FROM product
+		WHERE reception_id = ANY($1::uuid[])
+		ORDER BY date_time ASC
+	`, receptionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []domain.Product
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.ID, &p.Type, &p.ReceptionID, &p.DateTime, &p.CreatedBy); err != nil {
 			return nil, err
 		}
 		products = append(products, p)
 	}
-	return products, nil
+	return products, rows.Err()
 }
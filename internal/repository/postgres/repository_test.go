@@ -2,7 +2,9 @@ package postgres_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,6 +12,7 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 	"os"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 	"pvs/internal/repository/postgres"
 	"testing"
 	"time"
@@ -55,22 +58,82 @@ func TestMain(m *testing.M) {
 			password_hash TEXT NOT NULL,
 			role TEXT NOT NULL
 		);
+		CREATE TABLE city (
+			name TEXT PRIMARY KEY,
+			enabled BOOLEAN NOT NULL DEFAULT true,
+			created_at TIMESTAMP NOT NULL DEFAULT now(),
+			updated_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+		INSERT INTO city (name) VALUES ('Москва');
 		CREATE TABLE pvz (
 			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			city TEXT NOT NULL,
+			city TEXT NOT NULL REFERENCES city(name),
 			registration_date TIMESTAMP NOT NULL DEFAULT now()
 		);
+		CREATE TABLE pvz_staff (
+			pvz_id UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			assigned_by UUID,
+			assigned_at TIMESTAMP NOT NULL DEFAULT now(),
+			PRIMARY KEY (pvz_id, user_id)
+		);
 		CREATE TABLE reception (
 			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
 			pvz_id UUID NOT NULL REFERENCES pvz(id),
 			date_time TIMESTAMP NOT NULL DEFAULT now(),
-			status TEXT NOT NULL DEFAULT 'in_progress'
+			status TEXT NOT NULL DEFAULT 'in_progress',
+			close_idempotency_key TEXT,
+			created_by UUID,
+			closed_by UUID
+		);
+		CREATE TABLE refresh_token (
+			id UUID PRIMARY KEY,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			family_id UUID NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			expires_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			revoked_at TIMESTAMPTZ,
+			replaced_by UUID
+		);
+		CREATE TABLE revoked_token (
+			jti TEXT PRIMARY KEY,
+			expires_at TIMESTAMPTZ NOT NULL,
+			revoked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE TABLE login_attempt (
+			key TEXT PRIMARY KEY,
+			failures INT NOT NULL DEFAULT 0,
+			locked_until TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		CREATE TABLE idempotency_key (
+			key TEXT NOT NULL,
+			principal TEXT NOT NULL,
+			method TEXT NOT NULL,
+			path TEXT NOT NULL,
+			request_hash TEXT NOT NULL,
+			status_code INT,
+			content_type TEXT NOT NULL DEFAULT '',
+			response_body BYTEA,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (key, principal)
 		);
+		CREATE UNIQUE INDEX reception_one_in_progress_per_pvz ON reception (pvz_id) WHERE status = 'in_progress';
+		CREATE UNIQUE INDEX reception_close_idempotency_key ON reception (pvz_id, closed_by, close_idempotency_key)
+			WHERE close_idempotency_key IS NOT NULL;
+		CREATE TABLE product_type (
+			name TEXT PRIMARY KEY,
+			created_at TIMESTAMP NOT NULL DEFAULT now()
+		);
+		INSERT INTO product_type (name) VALUES ('book');
 		CREATE TABLE product (
 			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			type TEXT NOT NULL,
+			type TEXT NOT NULL REFERENCES product_type(name) ON UPDATE CASCADE,
 			reception_id UUID NOT NULL REFERENCES reception(id),
-			date_time TIMESTAMP NOT NULL DEFAULT now()
+			date_time TIMESTAMP NOT NULL DEFAULT now(),
+			created_by UUID
 		);
 	`)
 	if err != nil {
@@ -91,12 +154,14 @@ func TestAllRepositories(t *testing.T) {
 	receptionRepo := postgres.NewReceptionRepository(testDB)
 	productRepo := postgres.NewProductRepository(testDB)
 
-	err := userRepo.CreateUser(ctx, &domain.User{
+	created := &domain.User{
 		Email:    "test@mail.com",
 		Password: "hashed",
 		Role:     "employee",
-	})
+	}
+	err := userRepo.CreateUser(ctx, created)
 	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, created.ID)
 
 	user, err := userRepo.GetByEmail(ctx, "test@mail.com")
 	require.NoError(t, err)
@@ -105,26 +170,467 @@ func TestAllRepositories(t *testing.T) {
 	pvz, err := pvzRepo.CreatePVZ(ctx, "Москва")
 	require.NoError(t, err)
 
-	reception, err := receptionRepo.CreateReception(ctx, pvz.ID)
+	reception, err := receptionRepo.CreateReception(ctx, pvz.ID, created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "in_progress", reception.Status)
+	require.NotNil(t, reception.CreatedBy)
+	assert.Equal(t, created.ID, *reception.CreatedBy)
+	assert.Nil(t, reception.ClosedBy)
+
+	_, err = receptionRepo.CreateReception(ctx, pvz.ID, created.ID)
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
 
-	product, err := productRepo.AddProduct(ctx, reception.ID, "book")
+	product, err := productRepo.AddProduct(ctx, reception.ID, "book", created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "book", product.Type)
+	require.NotNil(t, product.CreatedBy)
+	assert.Equal(t, created.ID, *product.CreatedBy)
 
 	products, err := productRepo.GetProductsByReception(ctx, reception.ID)
 	require.NoError(t, err)
 	assert.Len(t, products, 1)
 
+	byReception, err := productRepo.ListByReceptionIDs(ctx, []uuid.UUID{reception.ID})
+	require.NoError(t, err)
+	assert.Len(t, byReception, 1)
+
+	byPVZ, err := receptionRepo.ListByPVZIDs(ctx, []uuid.UUID{pvz.ID}, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, byPVZ, 1)
+
 	err = productRepo.DeleteLastProduct(ctx, reception.ID)
 	require.NoError(t, err)
 
-	closed, err := receptionRepo.CloseLastReception(ctx, pvz.ID)
+	closed, err := receptionRepo.CloseLastReception(ctx, pvz.ID, created.ID, "close-key")
 	require.NoError(t, err)
 	assert.Equal(t, "close", closed.Status)
+	require.NotNil(t, closed.ClosedBy)
+	assert.Equal(t, created.ID, *closed.ClosedBy)
+
+	_, err = receptionRepo.CloseLastReception(ctx, pvz.ID, created.ID, "")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	replayed, err := receptionRepo.GetClosedByIdempotencyKey(ctx, pvz.ID, created.ID, "close-key")
+	require.NoError(t, err)
+	assert.Equal(t, closed.ID, replayed.ID)
 
-	pvzList, err := pvzRepo.ListPVZWithFilter(ctx, nil, nil, 1, 10)
+	_, err = receptionRepo.GetClosedByIdempotencyKey(ctx, pvz.ID, uuid.New(), "close-key")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	transactor := postgres.NewTransactor(testDB)
+	rollback := errors.New("rollback")
+	err = transactor.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, pvzRepo.LockPVZ(ctx, pvz.ID))
+		_, err := receptionRepo.CreateReception(ctx, pvz.ID, uuid.Nil)
+		require.NoError(t, err)
+		return rollback
+	})
+	assert.ErrorIs(t, err, rollback)
+
+	_, err = receptionRepo.GetOpenReception(ctx, pvz.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.ErrorIs(t, pvzRepo.LockPVZ(ctx, uuid.New()), repository.ErrNotFound)
+
+	pvzList, err := pvzRepo.ListPVZWithFilter(ctx, domain.PVZFilter{Page: 1, Limit: 10})
 	require.NoError(t, err)
 	assert.NotEmpty(t, pvzList)
 }
+
+func TestIdempotencyRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewIdempotencyRepository(testDB)
+
+	rec := &domain.IdempotencyRecord{
+		Key:         "key-1",
+		Principal:   "user-1",
+		Method:      "POST",
+		Path:        "/products",
+		RequestHash: "hash",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	_, reserved, err := repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	existing, reserved, err := repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.False(t, existing.Completed())
+
+	require.NoError(t, repo.Complete(ctx, rec.Key, rec.Principal, 201, "application/json", []byte(`{}`)))
+
+	existing, reserved, err = repo.Reserve(ctx, rec)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.Equal(t, []byte(`{}`), existing.ResponseBody)
+
+	other := *rec
+	other.Key = "key-2"
+	_, reserved, err = repo.Reserve(ctx, &other)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	require.NoError(t, repo.Release(ctx, other.Key, other.Principal))
+
+	_, reserved, err = repo.Reserve(ctx, &other)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	expired := *rec
+	expired.Key = "key-expired"
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, _, err = repo.Reserve(ctx, &expired)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteExpired(ctx))
+
+	var left []string
+	rows, err := testDB.Query(ctx, `SELECT key FROM idempotency_key ORDER BY key`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var key string
+		require.NoError(t, rows.Scan(&key))
+		left = append(left, key)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{rec.Key, other.Key}, left)
+}
+
+func TestProductTypeRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewProductTypeRepository(testDB)
+
+	pt, err := repo.CreateProductType(ctx, "игрушки")
+	require.NoError(t, err)
+	assert.Equal(t, "игрушки", pt.Name)
+
+	_, err = repo.CreateProductType(ctx, "игрушки")
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+
+	exists, err := repo.ProductTypeExists(ctx, "игрушки")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	types, err := repo.ListProductTypes(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, types)
+
+	pt, err = repo.RenameProductType(ctx, "игрушки", "игры")
+	require.NoError(t, err)
+	assert.Equal(t, "игры", pt.Name)
+	_, err = repo.RenameProductType(ctx, "игрушки", "куклы")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.RenameProductType(ctx, "игры", "book")
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+
+	require.NoError(t, repo.DeleteProductType(ctx, "игры"))
+	assert.ErrorIs(t, repo.DeleteProductType(ctx, "игры"), repository.ErrNotFound)
+}
+
+func TestCityRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewCityRepository(testDB)
+
+	city, err := repo.CreateCity(ctx, "Новосибирск", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Новосибирск", city.Name)
+	assert.False(t, city.Enabled)
+
+	_, err = repo.CreateCity(ctx, "Новосибирск", true)
+	assert.ErrorIs(t, err, repository.ErrAlreadyExists)
+
+	city, err = repo.SetCityEnabled(ctx, "Новосибирск", true)
+	require.NoError(t, err)
+	assert.True(t, city.Enabled)
+
+	_, err = repo.SetCityEnabled(ctx, "Атлантида", true)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	cities, err := repo.ListCities(ctx)
+	require.NoError(t, err)
+	assert.Len(t, cities, 2)
+}
+
+func TestTokenRepositories(t *testing.T) {
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(testDB)
+	refreshRepo := postgres.NewRefreshTokenRepository(testDB)
+	revokedRepo := postgres.NewRevokedTokenRepository(testDB)
+
+	user := &domain.User{Email: "tokens@mail.com", Password: "hashed", Role: "employee"}
+	require.NoError(t, userRepo.CreateUser(ctx, user))
+
+	found, err := userRepo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, found.Email)
+
+	family := uuid.New()
+	first := &domain.RefreshToken{ID: uuid.New(), UserID: user.ID, FamilyID: family, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	second := &domain.RefreshToken{ID: uuid.New(), UserID: user.ID, FamilyID: family, TokenHash: "hash-2", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, refreshRepo.CreateRefreshToken(ctx, first))
+	require.NoError(t, refreshRepo.CreateRefreshToken(ctx, second))
+
+	require.NoError(t, refreshRepo.MarkRotated(ctx, first.ID, second.ID))
+	rotated, err := refreshRepo.GetRefreshTokenForUpdate(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, rotated.RevokedAt)
+	assert.Equal(t, second.ID, *rotated.ReplacedBy)
+
+	require.NoError(t, refreshRepo.RevokeFamily(ctx, family))
+	revokedSecond, err := refreshRepo.GetRefreshTokenForUpdate(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.NotNil(t, revokedSecond.RevokedAt)
+
+	_, err = refreshRepo.GetRefreshTokenForUpdate(ctx, "missing")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	require.NoError(t, revokedRepo.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour)))
+	require.NoError(t, revokedRepo.RevokeToken(ctx, "jti-1", time.Now().Add(time.Hour)))
+	revoked, err := revokedRepo.IsRevoked(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = revokedRepo.IsRevoked(ctx, "jti-2")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewLoginAttemptRepository(testDB)
+
+	_, err := repo.GetLoginAttempt(ctx, "email:lock@mail.com")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	attempt, err := repo.RecordLoginFailure(ctx, "email:lock@mail.com", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures)
+
+	attempt, err = repo.RecordLoginFailure(ctx, "email:lock@mail.com", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempt.Failures)
+
+	until := time.Now().Add(time.Minute).UTC().Truncate(time.Microsecond)
+	require.NoError(t, repo.LockLogin(ctx, "email:lock@mail.com", until))
+	attempt, err = repo.GetLoginAttempt(ctx, "email:lock@mail.com")
+	require.NoError(t, err)
+	require.NotNil(t, attempt.LockedUntil)
+	assert.WithinDuration(t, until, *attempt.LockedUntil, time.Second)
+
+	require.NoError(t, repo.ResetLoginAttempts(ctx, "email:lock@mail.com"))
+	_, err = repo.GetLoginAttempt(ctx, "email:lock@mail.com")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repo.RecordLoginFailure(ctx, "ip:10.0.0.1", time.Minute)
+	require.NoError(t, err)
+	_, err = repo.RecordLoginFailure(ctx, "ip:10.0.0.2", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.LockLogin(ctx, "ip:10.0.0.2", time.Now().Add(time.Hour)))
+	_, err = testDB.Exec(ctx, `UPDATE login_attempt SET updated_at = now() - interval '1 hour'`)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteStale(ctx, time.Minute))
+	_, err = repo.GetLoginAttempt(ctx, "ip:10.0.0.1")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.GetLoginAttempt(ctx, "ip:10.0.0.2")
+	assert.NoError(t, err, "a running lockout is kept")
+}
+
+func TestStaffRepository(t *testing.T) {
+	ctx := context.Background()
+	pvzRepo := postgres.NewPVSRepository(testDB)
+	repo := postgres.NewStaffRepository(testDB)
+
+	pvz, err := pvzRepo.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+	user := &domain.User{Email: "staff@mail.com", Password: "hashed", Role: "employee"}
+	require.NoError(t, postgres.NewUserRepository(testDB).CreateUser(ctx, user))
+	userID, moderatorID := user.ID, uuid.New()
+
+	assigned, err := repo.IsAssigned(ctx, pvz.ID, userID)
+	require.NoError(t, err)
+	assert.False(t, assigned)
+
+	assignment, err := repo.AssignStaff(ctx, pvz.ID, userID, moderatorID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, assignment.UserID)
+	require.NotNil(t, assignment.AssignedBy)
+	assert.Equal(t, moderatorID, *assignment.AssignedBy)
+
+	again, err := repo.AssignStaff(ctx, pvz.ID, userID, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, moderatorID, *again.AssignedBy)
+
+	assigned, err = repo.IsAssigned(ctx, pvz.ID, userID)
+	require.NoError(t, err)
+	assert.True(t, assigned)
+
+	staff, err := repo.ListStaff(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Len(t, staff, 1)
+
+	_, err = repo.AssignStaff(ctx, uuid.New(), userID, moderatorID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.AssignStaff(ctx, pvz.ID, uuid.New(), moderatorID)
+	assert.Error(t, err, "user must exist")
+
+	require.NoError(t, repo.UnassignStaff(ctx, pvz.ID, userID))
+	assert.ErrorIs(t, repo.UnassignStaff(ctx, pvz.ID, userID), repository.ErrNotFound)
+}
+
+func TestPVZKeysetPagination(t *testing.T) {
+	ctx := context.Background()
+	pvzRepo := postgres.NewPVSRepository(testDB)
+
+	for i := 0; i < 5; i++ {
+		_, err := pvzRepo.CreatePVZ(ctx, "Москва")
+		require.NoError(t, err)
+	}
+
+	all, err := pvzRepo.ListPVZWithFilter(ctx, domain.PVZFilter{Page: 1, Limit: 1000})
+	require.NoError(t, err)
+
+	var (
+		seen     []uuid.UUID
+		after    *domain.PVZCursor
+		inserted int
+	)
+	for {
+		page, err := pvzRepo.ListPVZWithFilter(ctx, domain.PVZFilter{Limit: 2, After: after})
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		if len(page) > 2 {
+			page = page[:2]
+		}
+		for _, p := range page {
+			seen = append(seen, p.ID)
+		}
+		last := page[len(page)-1]
+		after = &domain.PVZCursor{RegistrationDate: last.RegistrationDate, ID: last.ID}
+
+		// a PVZ registered mid-scroll sorts before the cursor and must not shift later pages
+		_, err = pvzRepo.CreatePVZ(ctx, "Москва")
+		require.NoError(t, err)
+		inserted++
+	}
+
+	require.Len(t, seen, len(all))
+	total, err := pvzRepo.CountPVZ(ctx, domain.PVZFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, len(all)+inserted, total)
+	for i, p := range all {
+		assert.Equal(t, p.ID, seen[i])
+	}
+
+	second, err := pvzRepo.ListPVZWithFilter(ctx, domain.PVZFilter{Page: 2, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, second, 3)
+	fresh, err := pvzRepo.ListPVZWithFilter(ctx, domain.PVZFilter{Page: 1, Limit: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, fresh[2].ID, second[0].ID)
+}
+
+func TestReceptionHistory(t *testing.T) {
+	ctx := context.Background()
+	pvzRepo := postgres.NewPVSRepository(testDB)
+	receptionRepo := postgres.NewReceptionRepository(testDB)
+	productRepo := postgres.NewProductRepository(testDB)
+
+	pvz, err := pvzRepo.CreatePVZ(ctx, "Москва")
+	require.NoError(t, err)
+
+	found, err := pvzRepo.GetPVZ(ctx, pvz.ID)
+	require.NoError(t, err)
+	assert.Equal(t, pvz.ID, found.ID)
+	_, err = pvzRepo.GetPVZ(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	for i := 0; i < 3; i++ {
+		_, err := receptionRepo.CreateReception(ctx, pvz.ID, uuid.Nil)
+		require.NoError(t, err)
+		_, err = receptionRepo.CloseLastReception(ctx, pvz.ID, uuid.Nil, "")
+		require.NoError(t, err)
+	}
+	open, err := receptionRepo.CreateReception(ctx, pvz.ID, uuid.Nil)
+	require.NoError(t, err)
+	_, err = productRepo.AddProduct(ctx, open.ID, "обувь", uuid.Nil)
+	require.NoError(t, err)
+
+	all, err := receptionRepo.ListReceptions(ctx, pvz.ID, domain.ReceptionFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, open.ID, all[0].ID)
+
+	closed, err := receptionRepo.ListReceptions(ctx, pvz.ID, domain.ReceptionFilter{Status: domain.ReceptionClosed, Page: 2, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, closed, 1)
+
+	total, err := receptionRepo.CountReceptions(ctx, pvz.ID, domain.ReceptionFilter{Status: domain.ReceptionClosed})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+
+	future := time.Now().Add(time.Hour)
+	total, err = receptionRepo.CountReceptions(ctx, pvz.ID, domain.ReceptionFilter{StartDate: &future})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	rec, err := receptionRepo.GetReception(ctx, open.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReceptionInProgress, rec.Status)
+	_, err = receptionRepo.GetReception(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	products, err := productRepo.GetProductsByReception(ctx, open.ID)
+	require.NoError(t, err)
+	assert.Len(t, products, 1)
+}
+
+func TestMigrationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewMigrationRepository(testDB)
+
+	version, err := repo.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version, "no goose table yet")
+
+	_, err = testDB.Exec(ctx, `
+		CREATE TABLE goose_db_version (
+			id SERIAL PRIMARY KEY,
+			version_id BIGINT NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP DEFAULT now()
+		);
+		INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, true), (1, true), (2, true), (3, true), (3, false);
+	`)
+	require.NoError(t, err)
+	t.Cleanup(func() { testDB.Exec(ctx, `DROP TABLE goose_db_version`) })
+
+	version, err = repo.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version, "version 3 was rolled back")
+}
+
+func TestUserAdminQueries(t *testing.T) {
+	ctx := context.Background()
+	repo := postgres.NewUserRepository(testDB)
+
+	user := &domain.User{Email: "ops-" + uuid.NewString() + "@example.com", Password: "hash", Role: "employee"}
+	require.NoError(t, repo.CreateUser(ctx, user))
+
+	users, err := repo.ListUsers(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, users, domain.User{ID: user.ID, Email: user.Email, Role: user.Role})
+	for _, u := range users {
+		assert.Empty(t, u.Password, "password hashes are not listed")
+	}
+
+	updated, err := repo.SetRole(ctx, user.ID, "moderator")
+	require.NoError(t, err)
+	assert.Equal(t, "moderator", updated.Role)
+
+	_, err = repo.SetRole(ctx, uuid.New(), "moderator")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
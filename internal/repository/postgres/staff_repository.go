@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+type PostgresStaffRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStaffRepository(pool *pgxpool.Pool) *PostgresStaffRepository {
+	return &PostgresStaffRepository{pool: pool}
+}
+
+func (r *PostgresStaffRepository) AssignStaff(ctx context.Context, pvzID, userID, assignedBy uuid.UUID) (*domain.StaffAssignment, error) {
+	var a domain.StaffAssignment
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO pvz_staff (pvz_id, user_id, assigned_by) VALUES ($1, $2, $3)
+		ON CONFLICT (pvz_id, user_id) DO UPDATE SET pvz_id = EXCLUDED.pvz_id
+		RETURNING pvz_id, user_id, assigned_by, assigned_at
+	`, pvzID, userID, nullUUID(assignedBy)).Scan(&a.PVZID, &a.UserID, &a.AssignedBy, &a.AssignedAt)
+	if isForeignKeyViolation(err) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PostgresStaffRepository) UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `
+		DELETE FROM pvz_staff WHERE pvz_id = $1 AND user_id = $2
+	`, pvzID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresStaffRepository) ListStaff(ctx context.Context, pvzID uuid.UUID) ([]domain.StaffAssignment, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT pvz_id, user_id, assigned_by, assigned_at FROM pvz_staff
+		WHERE pvz_id = $1
+		ORDER BY assigned_at, user_id
+	`, pvzID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var staff []domain.StaffAssignment
+	for rows.Next() {
+		var a domain.StaffAssignment
+		if err := rows.Scan(&a.PVZID, &a.UserID, &a.AssignedBy, &a.AssignedAt); err != nil {
+			return nil, err
+		}
+		staff = append(staff, a)
+	}
+	return staff, rows.Err()
+}
+
+func (r *PostgresStaffRepository) IsAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	var assigned bool
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM pvz_staff WHERE pvz_id = $1 AND user_id = $2)
+	`, pvzID, userID).Scan(&assigned)
+	return assigned, err
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"pvs/internal/domain"
+)
+
+type PostgresRefreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenRepository(pool *pgxpool.Pool) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{pool: pool}
+}
+
+func (r *PostgresRefreshTokenRepository) CreateRefreshToken(ctx context.Context, t *domain.RefreshToken) error {
+	return conn(ctx, r.pool).QueryRow(ctx, `
+		INSERT INTO refresh_token (id, user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`, t.ID, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt).Scan(&t.CreatedAt)
+}
+
+func (r *PostgresRefreshTokenRepository) GetRefreshTokenForUpdate(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, replaced_by
+		FROM refresh_token
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &t.CreatedAt, &t.RevokedAt, &t.ReplacedBy)
+	if err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) MarkRotated(ctx context.Context, id, replacedBy uuid.UUID) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE refresh_token SET revoked_at = now(), replaced_by = $2 WHERE id = $1
+	`, id, replacedBy)
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		UPDATE refresh_token SET revoked_at = now()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) DeleteExpiredRefreshTokens(ctx context.Context) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM refresh_token WHERE expires_at < now()`)
+	return err
+}
+
+type PostgresRevokedTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRevokedTokenRepository(pool *pgxpool.Pool) *PostgresRevokedTokenRepository {
+	return &PostgresRevokedTokenRepository{pool: pool}
+}
+
+func (r *PostgresRevokedTokenRepository) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO revoked_token (jti, expires_at) VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, expiresAt)
+	return err
+}
+
+func (r *PostgresRevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := conn(ctx, r.pool).QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM revoked_token WHERE jti = $1)
+	`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+func (r *PostgresRevokedTokenRepository) DeleteExpiredRevocations(ctx context.Context) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM revoked_token WHERE expires_at < now()`)
+	return err
+}
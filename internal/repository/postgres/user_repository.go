@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 )
 
 type PostgresUserRepository struct {
@@ -15,15 +17,65 @@ func NewUserRepository(pool *pgxpool.Pool) *PostgresUserRepository {
 }
 
 func (r *PostgresUserRepository) CreateUser(ctx context.Context, u *domain.User) error {
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO users (id, email, password_hash, role) VALUES (gen_random_uuid(), $1, $2, $3)`,
-		u.Email, u.Password, u.Role)
+	err := conn(ctx, r.pool).QueryRow(ctx,
+		`INSERT INTO users (id, email, password_hash, role) VALUES (gen_random_uuid(), $1, $2, $3) RETURNING id`,
+		u.Email, u.Password, u.Role).Scan(&u.ID)
+	if isUniqueViolation(err) {
+		return repository.ErrAlreadyExists
+	}
 	return err
 }
 
+func (r *PostgresUserRepository) EnsureUser(ctx context.Context, u domain.User) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO users (id, email, password_hash, role) VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, u.ID, u.Email, u.Password, u.Role)
+	return err
+}
+
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, `SELECT id, email, password_hash, role FROM users WHERE id=$1`, id)
+	var user domain.User
+	if err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Role); err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &user, nil
+}
+
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	row := r.pool.QueryRow(ctx, `SELECT id, email, password_hash, role FROM users WHERE email=$1`, email)
+	row := conn(ctx, r.pool).QueryRow(ctx, `SELECT id, email, password_hash, role FROM users WHERE email=$1`, email)
+	var user domain.User
+	if err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Role); err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &user, nil
+}
+
+func (r *PostgresUserRepository) ListUsers(ctx context.Context) ([]domain.User, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT id, email, role FROM users ORDER BY email`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Email, &user.Role); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *PostgresUserRepository) SetRole(ctx context.Context, id uuid.UUID, role string) (*domain.User, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx,
+		`UPDATE users SET role = $2 WHERE id = $1 RETURNING id, email, role`, id, role)
 	var user domain.User
-	err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Role)
-	return &user, err
+	if err := row.Scan(&user.ID, &user.Email, &user.Role); err != nil {
+		return nil, mapNoRows(err)
+	}
+	return &user, nil
 }
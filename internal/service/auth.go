@@ -2,59 +2,252 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
+	"sync"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"pvs/internal/domain"
 	"pvs/internal/repository"
 )
 
 type AuthService struct {
-	repo      repository.UserRepository
-	jwtSecret []byte
+	repo        repository.UserRepository
+	tokens      *TokenIssuer
+	sessions    repository.RefreshTokenRepository
+	revocations *RevocationList
+	limiter     *LoginLimiter
+	tx          repository.Transactor
+	passwords   PasswordPolicy
+	refreshTTL  time.Duration
 }
 
-func NewAuthService(repo repository.UserRepository, secret []byte) *AuthService {
-	return &AuthService{repo: repo, jwtSecret: secret}
+func NewAuthService(
+	repo repository.UserRepository,
+	tokens *TokenIssuer,
+	sessions repository.RefreshTokenRepository,
+	revocations *RevocationList,
+	limiter *LoginLimiter,
+	tx repository.Transactor,
+	passwords PasswordPolicy,
+	refreshTTL time.Duration,
+) *AuthService {
+	return &AuthService{
+		repo:        repo,
+		tokens:      tokens,
+		sessions:    sessions,
+		revocations: revocations,
+		limiter:     limiter,
+		tx:          tx,
+		passwords:   passwords,
+		refreshTTL:  refreshTTL,
+	}
 }
 
-func (s *AuthService) Register(ctx context.Context, email, password, role string) (string, error) {
-	if email == "" || password == "" {
-		return "", errors.New("email/password required")
-	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+func (s *AuthService) Register(ctx context.Context, email, password, role string) (*domain.TokenPair, error) {
+	user, err := createUser(ctx, s.repo, s.passwords, email, password, role)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	user := &domain.User{
-		Email:    email,
-		Password: string(hash),
-		Role:     role,
-	}
-	if err := s.repo.CreateUser(ctx, user); err != nil {
+	return s.issuePair(ctx, *user, uuid.New())
+}
+
+// DummyLogin issues a /dummyLogin token. The fixed user of the role gets a
+// users row on first use so it can be assigned to a PVZ; '!' is not a bcrypt
+// hash, so nobody can log in as it with a password.
+func (s *AuthService) DummyLogin(ctx context.Context, role string) (string, error) {
+	user := DummyUser(role)
+	account := user
+	account.Password = "!"
+	if err := s.repo.EnsureUser(ctx, account); err != nil {
 		return "", err
 	}
-	return GenerateToken(s.jwtSecret, role)
+	return s.tokens.IssueDummy(user)
 }
 
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, error) {
+func (s *AuthService) Login(ctx context.Context, email, password, clientIP string) (*domain.TokenPair, error) {
+	email = NormalizeEmail(email)
+	// such an address can never have been registered; refusing it here keeps
+	// made-up strings out of login_attempt
+	if err := validateEmail(email); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	// a locked key refuses before the password is checked, otherwise a
+	// locked client could keep guessing and tell the right guess by the reply
+	emailLocked, ipLocked, err := s.limiter.Check(ctx, email, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if emailLocked || ipLocked {
+		return nil, ErrTooManyLoginAttempts
+	}
+
 	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	hash := dummyPasswordHash()
+	if user != nil {
+		hash = []byte(user.Password)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || user == nil {
+		if err := s.limiter.Fail(ctx, email, clientIP); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	// the IP key is left alone: one valid account must not let its owner
+	// reset the counter and keep spraying other emails
+	if err := s.limiter.Reset(ctx, email); err != nil {
+		return nil, err
+	}
+	return s.issuePair(ctx, *user, uuid.New())
+}
+
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var (
+		pair   *domain.TokenPair
+		reused bool
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.sessions.GetRefreshTokenForUpdate(ctx, hashRefreshToken(refreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			reused = true
+			log.Printf("⚠️  refresh token reuse detected, revoking family %s of user %s", current.FamilyID, current.UserID)
+			return s.sessions.RevokeFamily(ctx, current.FamilyID)
+		}
+		if time.Now().After(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		user, err := s.repo.GetByID(ctx, current.UserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		var next *domain.RefreshToken
+		pair, next, err = s.newPair(*user, current.FamilyID)
+		if err != nil {
+			return err
+		}
+		if err := s.sessions.CreateRefreshToken(ctx, next); err != nil {
+			return err
+		}
+		return s.sessions.MarkRotated(ctx, current.ID, next.ID)
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", err
+	if reused {
+		return nil, ErrInvalidRefreshToken
+	}
+	return pair, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, principal domain.Principal, refreshToken string) error {
+	if err := s.revocations.Revoke(ctx, principal.TokenID, principal.ExpiresAt); err != nil {
+		return err
+	}
+	if refreshToken == "" {
+		return nil
+	}
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.sessions.GetRefreshTokenForUpdate(ctx, hashRefreshToken(refreshToken))
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if current.UserID != principal.UserID {
+			return nil
+		}
+		return s.sessions.RevokeFamily(ctx, current.FamilyID)
+	})
+}
+
+func (s *AuthService) PruneExpired(ctx context.Context) error {
+	if err := s.sessions.DeleteExpiredRefreshTokens(ctx); err != nil {
+		return err
 	}
-	return GenerateToken(s.jwtSecret, user.Role)
+	return s.revocations.Prune(ctx)
 }
 
-func GenerateToken(secret []byte, role string) (string, error) {
-	claims := jwt.MapClaims{
-		"user_type": role,
-		"exp":       time.Now().Add(24 * time.Hour).Unix(),
+func (s *AuthService) issuePair(ctx context.Context, user domain.User, familyID uuid.UUID) (*domain.TokenPair, error) {
+	pair, refresh, err := s.newPair(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.CreateRefreshToken(ctx, refresh); err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return pair, nil
+}
+
+func (s *AuthService) newPair(user domain.User, familyID uuid.UUID) (*domain.TokenPair, *domain.RefreshToken, error) {
+	access, err := s.tokens.Issue(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	refresh := &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	pair := &domain.TokenPair{
+		AccessToken:  access,
+		RefreshToken: refreshToken,
+		ExpiresIn:    s.tokens.TTL(),
+	}
+	return pair, refresh, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -3,14 +3,21 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
 	"testing"
+	"time"
 
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 	"pvs/internal/service"
+	"pvs/internal/transport/middleware"
 )
 
 type mockUserRepo struct {
@@ -22,6 +29,10 @@ func (m *mockUserRepo) CreateUser(ctx context.Context, user *domain.User) error
 	return args.Error(0)
 }
 
+func (m *mockUserRepo) EnsureUser(ctx context.Context, user domain.User) error {
+	return m.Called(ctx, user).Error(0)
+}
+
 func (m *mockUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	args := m.Called(ctx, email)
 	if u := args.Get(0); u != nil {
@@ -30,61 +41,256 @@ func (m *mockUserRepo) GetByEmail(ctx context.Context, email string) (*domain.Us
 	return nil, args.Error(1)
 }
 
-var jwtSecret = []byte("secret")
+func (m *mockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	args := m.Called(ctx, id)
+	if u := args.Get(0); u != nil {
+		return u.(*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockUserRepo) ListUsers(ctx context.Context) ([]domain.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.User), args.Error(1)
+}
+
+func (m *mockUserRepo) SetRole(ctx context.Context, id uuid.UUID, role string) (*domain.User, error) {
+	args := m.Called(ctx, id, role)
+	if u := args.Get(0); u != nil {
+		return u.(*domain.User), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type memorySessions struct {
+	byHash map[string]*domain.RefreshToken
+}
+
+func (m *memorySessions) CreateRefreshToken(_ context.Context, t *domain.RefreshToken) error {
+	t.CreatedAt = time.Now()
+	m.byHash[t.TokenHash] = t
+	return nil
+}
+
+func (m *memorySessions) GetRefreshTokenForUpdate(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	t, ok := m.byHash[tokenHash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (m *memorySessions) MarkRotated(_ context.Context, id, replacedBy uuid.UUID) error {
+	for _, t := range m.byHash {
+		if t.ID == id {
+			now := time.Now()
+			t.RevokedAt, t.ReplacedBy = &now, &replacedBy
+		}
+	}
+	return nil
+}
+
+func (m *memorySessions) RevokeFamily(_ context.Context, familyID uuid.UUID) error {
+	for _, t := range m.byHash {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *memorySessions) DeleteExpiredRefreshTokens(context.Context) error {
+	return nil
+}
+
+func (m *memorySessions) active() int {
+	n := 0
+	for _, t := range m.byHash {
+		if t.RevokedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+type memoryRevocations struct {
+	revoked map[string]time.Time
+	lookups int
+}
+
+func (m *memoryRevocations) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	m.revoked[jti] = expiresAt
+	return nil
+}
+
+func (m *memoryRevocations) IsRevoked(_ context.Context, jti string) (bool, error) {
+	m.lookups++
+	_, ok := m.revoked[jti]
+	return ok, nil
+}
+
+func (m *memoryRevocations) DeleteExpiredRevocations(context.Context) error {
+	return nil
+}
+
+var (
+	jwtSecret = []byte("secret")
+	tokens    = service.NewTokenIssuer(jwtSecret, "", "pvz-test", "pvz-test-api", time.Hour)
+)
+
+type memoryLoginAttempts struct {
+	attempts map[string]*domain.LoginAttempt
+}
+
+func (m *memoryLoginAttempts) GetLoginAttempt(_ context.Context, key string) (*domain.LoginAttempt, error) {
+	a, ok := m.attempts[key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (m *memoryLoginAttempts) RecordLoginFailure(_ context.Context, key string, _ time.Duration) (*domain.LoginAttempt, error) {
+	a, ok := m.attempts[key]
+	if !ok {
+		a = &domain.LoginAttempt{Key: key}
+		m.attempts[key] = a
+	}
+	a.Failures++
+	a.UpdatedAt = time.Now()
+	cp := *a
+	return &cp, nil
+}
+
+func (m *memoryLoginAttempts) LockLogin(_ context.Context, key string, until time.Time) error {
+	m.attempts[key].LockedUntil = &until
+	return nil
+}
+
+func (m *memoryLoginAttempts) ResetLoginAttempts(_ context.Context, key string) error {
+	delete(m.attempts, key)
+	return nil
+}
+
+func (m *memoryLoginAttempts) DeleteStale(_ context.Context, window time.Duration) error {
+	now := time.Now()
+	for key, a := range m.attempts {
+		if a.UpdatedAt.Before(now.Add(-window)) && (a.LockedUntil == nil || a.LockedUntil.Before(now)) {
+			delete(m.attempts, key)
+		}
+	}
+	return nil
+}
+
+var passwordPolicy = service.PasswordPolicy{MinLength: 8, RequireLetter: true, RequireDigit: true}
+
+type authFixture struct {
+	svc         *service.AuthService
+	sessions    *memorySessions
+	revocations *service.RevocationList
+	attempts    *memoryLoginAttempts
+}
+
+func newAuthFixture(repo *mockUserRepo) *authFixture {
+	f := &authFixture{
+		sessions:    &memorySessions{byHash: make(map[string]*domain.RefreshToken)},
+		revocations: service.NewRevocationList(&memoryRevocations{revoked: make(map[string]time.Time)}, time.Minute),
+		attempts:    &memoryLoginAttempts{attempts: make(map[string]*domain.LoginAttempt)},
+	}
+	limiter := service.NewLoginLimiter(f.attempts, service.LoginThrottle{
+		MaxFailures:   3,
+		MaxIPFailures: 5,
+		Window:        time.Minute,
+		Lockout:       time.Minute,
+		MaxLockout:    time.Hour,
+	})
+	f.svc = service.NewAuthService(repo, tokens, f.sessions, f.revocations, limiter, new(fakeTransactor), passwordPolicy, 24*time.Hour)
+	return f
+}
+
+func newAuthService(repo *mockUserRepo) *service.AuthService {
+	return newAuthFixture(repo).svc
+}
 
 func TestRegister_Success(t *testing.T) {
 	repo := new(mockUserRepo)
-	svc := service.NewAuthService(repo, jwtSecret)
+	svc := newAuthService(repo)
 
 	email := "test@example.com"
-	password := "securepass"
+	password := "securepass1"
 	role := "employee"
+	userID := uuid.New()
 
 	repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
 		err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 		return user.Email == email && user.Role == role && err == nil
-	})).Return(nil)
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = userID
+	}).Return(nil)
 
-	token, err := svc.Register(context.Background(), email, password, role)
+	pair, err := svc.Register(context.Background(), email, password, role)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	require.NotNil(t, pair)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
 
 	claims := jwt.MapClaims{}
-	_, err = jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+	_, err = jwt.ParseWithClaims(pair.AccessToken, claims, func(t *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
 	})
 	assert.NoError(t, err)
 	assert.Equal(t, role, claims["user_type"])
+	assert.Equal(t, userID.String(), claims["sub"])
+	assert.Equal(t, email, claims["email"])
+	assert.Equal(t, "pvz-test", claims["iss"])
+	assert.Equal(t, "pvz-test-api", claims["aud"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotEmpty(t, claims["iat"])
 
 	repo.AssertExpectations(t)
 }
 
 func TestRegister_InvalidInput(t *testing.T) {
-	svc := service.NewAuthService(nil, jwtSecret)
+	svc := newAuthService(nil)
 
-	token, err := svc.Register(context.Background(), "", "pass", "employee")
-	assert.Empty(t, token)
+	pair, err := svc.Register(context.Background(), "", "pass", "employee")
+	assert.Nil(t, pair)
 	assert.EqualError(t, err, "email/password required")
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
 }
 
 func TestRegister_CreateUserError(t *testing.T) {
 	repo := new(mockUserRepo)
-	svc := service.NewAuthService(repo, jwtSecret)
+	svc := newAuthService(repo)
 
 	email := "test@example.com"
-	password := "securepass"
+	password := "securepass1"
 	role := "employee"
 
 	repo.On("CreateUser", mock.Anything, mock.Anything).Return(errors.New("db error"))
 
-	token, err := svc.Register(context.Background(), email, password, role)
-	assert.Empty(t, token)
+	pair, err := svc.Register(context.Background(), email, password, role)
+	assert.Nil(t, pair)
 	assert.EqualError(t, err, "db error")
 }
 
+func TestRegister_DuplicateEmail(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+
+	repo.On("CreateUser", mock.Anything, mock.Anything).Return(repository.ErrAlreadyExists)
+
+	pair, err := svc.Register(context.Background(), "test@example.com", "securepass1", "employee")
+	assert.Nil(t, pair)
+	assert.ErrorIs(t, err, service.ErrUserAlreadyExists)
+}
+
 func TestLogin_Success(t *testing.T) {
 	repo := new(mockUserRepo)
-	svc := service.NewAuthService(repo, jwtSecret)
+	svc := newAuthService(repo)
 
 	email := "test@example.com"
 	password := "mypassword"
@@ -97,27 +303,29 @@ func TestLogin_Success(t *testing.T) {
 
 	repo.On("GetByEmail", mock.Anything, email).Return(user, nil)
 
-	token, err := svc.Login(context.Background(), email, password)
+	pair, err := svc.Login(context.Background(), email, password, "10.0.0.1")
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	require.NotNil(t, pair)
+	assert.NotEmpty(t, pair.AccessToken)
+	assert.NotEmpty(t, pair.RefreshToken)
 
 	repo.AssertExpectations(t)
 }
 
 func TestLogin_UserNotFound(t *testing.T) {
 	repo := new(mockUserRepo)
-	svc := service.NewAuthService(repo, jwtSecret)
+	svc := newAuthService(repo)
 
-	repo.On("GetByEmail", mock.Anything, "no@user.com").Return(nil, errors.New("not found"))
+	repo.On("GetByEmail", mock.Anything, "no@user.com").Return(nil, repository.ErrNotFound)
 
-	token, err := svc.Login(context.Background(), "no@user.com", "pass")
-	assert.Empty(t, token)
-	assert.EqualError(t, err, "not found")
+	pair, err := svc.Login(context.Background(), "no@user.com", "pass", "10.0.0.1")
+	assert.Nil(t, pair)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
 }
 
 func TestLogin_InvalidPassword(t *testing.T) {
 	repo := new(mockUserRepo)
-	svc := service.NewAuthService(repo, jwtSecret)
+	svc := newAuthService(repo)
 
 	email := "test@example.com"
 	wrongPassword := "wrongpass"
@@ -128,10 +336,294 @@ func TestLogin_InvalidPassword(t *testing.T) {
 
 	repo.On("GetByEmail", mock.Anything, email).Return(user, nil)
 
-	token, err := svc.Login(context.Background(), email, wrongPassword)
-	assert.Empty(t, token)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "hashedPassword")
+	pair, err := svc.Login(context.Background(), email, wrongPassword, "10.0.0.1")
+	assert.Nil(t, pair)
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+
+	repo.AssertExpectations(t)
+}
+
+func TestDummyUser_StablePerRole(t *testing.T) {
+	employee := service.DummyUser("employee")
+	assert.Equal(t, employee.ID, service.DummyUser("employee").ID)
+	assert.NotEqual(t, employee.ID, service.DummyUser("moderator").ID)
+	assert.Equal(t, "employee", employee.Role)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+	svc, sessions := f.svc, f.sessions
+
+	user := &domain.User{ID: uuid.New(), Email: "test@example.com", Role: "employee"}
+	repo.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = user.ID
+	}).Return(nil)
+	repo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	first, err := svc.Register(context.Background(), user.Email, "securepass1", user.Role)
+	require.NoError(t, err)
+
+	second, err := svc.Refresh(context.Background(), first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.NotEmpty(t, second.AccessToken)
+	assert.Equal(t, time.Hour, second.ExpiresIn)
+	assert.Equal(t, 1, sessions.active())
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+	svc, sessions := f.svc, f.sessions
+
+	user := &domain.User{ID: uuid.New(), Email: "test@example.com", Role: "employee"}
+	repo.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = user.ID
+	}).Return(nil)
+	repo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	first, err := svc.Register(context.Background(), user.Email, "securepass1", user.Role)
+	require.NoError(t, err)
+	second, err := svc.Refresh(context.Background(), first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = svc.Refresh(context.Background(), first.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	assert.Equal(t, 0, sessions.active())
+
+	_, err = svc.Refresh(context.Background(), second.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestRefresh_UnknownToken(t *testing.T) {
+	svc := newAuthService(new(mockUserRepo))
+
+	pair, err := svc.Refresh(context.Background(), "does-not-exist")
+	assert.Nil(t, pair)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestLogout_RevokesAccessAndRefreshTokens(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+	svc, sessions, revocations := f.svc, f.sessions, f.revocations
+
+	userID := uuid.New()
+	repo.On("CreateUser", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.User).ID = userID
+	}).Return(nil)
+
+	pair, err := svc.Register(context.Background(), "test@example.com", "securepass1", "employee")
+	require.NoError(t, err)
+
+	principal := domain.Principal{UserID: userID, Role: "employee", TokenID: "jti-1", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, svc.Logout(context.Background(), principal, pair.RefreshToken))
+
+	revoked, err := revocations.IsRevoked(context.Background(), "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Equal(t, 0, sessions.active())
+}
 
+func TestRegister_NormalizesEmail(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+
+	repo.On("CreateUser", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
+		return user.Email == "test@example.com"
+	})).Return(nil)
+
+	_, err := svc.Register(context.Background(), "  Test@Example.COM ", "securepass1", "employee")
+	assert.NoError(t, err)
 	repo.AssertExpectations(t)
 }
+
+func TestRegister_InvalidEmail(t *testing.T) {
+	svc := newAuthService(new(mockUserRepo))
+
+	for _, email := range []string{"not-an-email", "user@localhost", "John <john@example.com>", "a@b@c.com"} {
+		_, err := svc.Register(context.Background(), email, "securepass1", "employee")
+		assert.ErrorIs(t, err, service.ErrInvalidInput, email)
+	}
+}
+
+func TestRegister_WeakPassword(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+
+	_, err := svc.Register(context.Background(), "test@example.com", "short1", "employee")
+	assert.ErrorIs(t, err, service.ErrWeakPassword)
+	repo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestLogin_LocksOutAfterRepeatedFailures(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("correct1"), bcrypt.DefaultCost)
+	user := &domain.User{ID: uuid.New(), Email: "test@example.com", Password: string(hashed), Role: "employee"}
+	repo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := f.svc.Login(context.Background(), "test@example.com", "wrong", "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+
+	_, err := f.svc.Login(context.Background(), "Test@Example.com", "correct1", "10.0.0.2")
+	assert.ErrorIs(t, err, service.ErrTooManyLoginAttempts)
+
+	repo.On("GetByEmail", mock.Anything, "other@example.com").Return(nil, repository.ErrNotFound)
+	_, err = f.svc.Login(context.Background(), "other@example.com", "whatever", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials, "the IP threshold is higher than the email one")
+}
+
+func TestLogin_IPLockout(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("correct1"), bcrypt.DefaultCost)
+	user := &domain.User{ID: uuid.New(), Email: "test@example.com", Password: string(hashed), Role: "employee"}
+	repo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+	repo.On("GetByEmail", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+
+	for i := 0; i < 5; i++ {
+		_, err := f.svc.Login(context.Background(), fmt.Sprintf("guess%d@example.com", i), "wrong", "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+
+	_, err := f.svc.Login(context.Background(), "guess9@example.com", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrTooManyLoginAttempts, "wrong password from a locked IP")
+
+	_, err = f.svc.Login(context.Background(), "guess9@example.com", "wrong", "10.0.0.2")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials, "other addresses are not affected")
+
+	_, err = f.svc.Login(context.Background(), "test@example.com", "correct1", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrTooManyLoginAttempts, "a locked IP does not get its guesses checked")
+	repo.AssertNumberOfCalls(t, "GetByEmail", 6)
+}
+
+func TestLogin_SuccessKeepsIPFailures(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("correct1"), bcrypt.DefaultCost)
+	user := &domain.User{ID: uuid.New(), Email: "own@example.com", Password: string(hashed), Role: "employee"}
+	repo.On("GetByEmail", mock.Anything, "own@example.com").Return(user, nil)
+	repo.On("GetByEmail", mock.Anything, mock.Anything).Return(nil, repository.ErrNotFound)
+
+	for i := 0; i < 4; i++ {
+		_, err := f.svc.Login(context.Background(), fmt.Sprintf("victim%d@example.com", i), "wrong", "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+	_, err := f.svc.Login(context.Background(), "own@example.com", "correct1", "10.0.0.1")
+	require.NoError(t, err)
+
+	_, err = f.svc.Login(context.Background(), "victim9@example.com", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	_, err = f.svc.Login(context.Background(), "victim9@example.com", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrTooManyLoginAttempts, "logging in to one's own account does not reset the IP")
+}
+
+func TestLogin_InvalidEmailSkipsLimiter(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+
+	for _, email := range []string{"not-an-email", strings.Repeat("a", 250) + "@example.com"} {
+		_, err := f.svc.Login(context.Background(), email, "wrong", "10.0.0.1")
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	}
+	assert.Empty(t, f.attempts.attempts)
+	repo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
+func TestLoginLimiter_PruneStale(t *testing.T) {
+	lockedUntil := time.Now().Add(time.Hour)
+	attempts := &memoryLoginAttempts{attempts: map[string]*domain.LoginAttempt{
+		"email:old@example.com":    {UpdatedAt: time.Now().Add(-time.Hour)},
+		"email:recent@example.com": {UpdatedAt: time.Now()},
+		"ip:10.0.0.1":              {UpdatedAt: time.Now().Add(-time.Hour), LockedUntil: &lockedUntil},
+	}}
+	limiter := service.NewLoginLimiter(attempts, service.LoginThrottle{Window: time.Minute})
+
+	require.NoError(t, limiter.PruneStale(context.Background()))
+	assert.NotContains(t, attempts.attempts, "email:old@example.com")
+	assert.Contains(t, attempts.attempts, "email:recent@example.com")
+	assert.Contains(t, attempts.attempts, "ip:10.0.0.1", "a running lockout is kept")
+}
+
+func TestLogin_SuccessResetsFailures(t *testing.T) {
+	repo := new(mockUserRepo)
+	f := newAuthFixture(repo)
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("correct1"), bcrypt.DefaultCost)
+	user := &domain.User{ID: uuid.New(), Email: "test@example.com", Password: string(hashed), Role: "employee"}
+	repo.On("GetByEmail", mock.Anything, "test@example.com").Return(user, nil)
+
+	_, err := f.svc.Login(context.Background(), "test@example.com", "wrong", "10.0.0.1")
+	assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+
+	_, err = f.svc.Login(context.Background(), "test@example.com", "correct1", "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotContains(t, f.attempts.attempts, "email:test@example.com")
+	assert.Contains(t, f.attempts.attempts, "ip:10.0.0.1")
+}
+
+func TestLogin_RepositoryError(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+
+	repo.On("GetByEmail", mock.Anything, "test@example.com").Return(nil, errors.New("db down"))
+
+	_, err := svc.Login(context.Background(), "test@example.com", "pass", "")
+	assert.EqualError(t, err, "db down")
+}
+
+func TestTokenIssuer_StampsKeyID(t *testing.T) {
+	user := domain.User{ID: uuid.New(), Email: "a@example.com", Role: "employee"}
+	oldToken, err := service.NewTokenIssuer([]byte("old-secret"), "k1", "pvz-test", "pvz-test-api", time.Hour).Issue(user)
+	require.NoError(t, err)
+	newToken, err := service.NewTokenIssuer([]byte("new-secret"), "k2", "pvz-test", "pvz-test-api", time.Hour).Issue(user)
+	require.NoError(t, err)
+
+	keys := middleware.NewKeySet()
+	keys.AddHMAC("k2", []byte("new-secret"))
+	keys.AddHMAC("k1", []byte("old-secret"))
+
+	for kid, raw := range map[string]string{"k1": oldToken, "k2": newToken} {
+		token, err := jwt.Parse(raw, keys.Key)
+		require.NoError(t, err, kid)
+		assert.Equal(t, kid, token.Header["kid"])
+	}
+
+	keys.Remove("k1")
+	_, err = jwt.Parse(oldToken, keys.Key)
+	assert.ErrorIs(t, err, middleware.ErrUnknownKeyID)
+}
+
+func TestDummyLogin_EnsuresUser(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+
+	user := service.DummyUser("employee")
+	repo.On("EnsureUser", mock.Anything, domain.User{ID: user.ID, Email: user.Email, Password: "!", Role: "employee"}).Return(nil)
+
+	token, err := svc.DummyLogin(context.Background(), "employee")
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return jwtSecret, nil })
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.String(), claims["sub"])
+	assert.Equal(t, true, claims["dummy"])
+}
+
+func TestDummyLogin_RepositoryError(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := newAuthService(repo)
+	repo.On("EnsureUser", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	_, err := svc.DummyLogin(context.Background(), "moderator")
+	assert.EqualError(t, err, "db down")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+const maxCityNameLength = 128
+
+type CityCatalog struct {
+	repo repository.CityRepository
+	ttl  time.Duration
+
+	mu       sync.RWMutex
+	enabled  map[string]struct{}
+	loadedAt time.Time
+}
+
+func NewCityCatalog(repo repository.CityRepository, ttl time.Duration) *CityCatalog {
+	return &CityCatalog{repo: repo, ttl: ttl}
+}
+
+func (c *CityCatalog) IsEnabled(ctx context.Context, city string) (bool, error) {
+	c.mu.RLock()
+	fresh := c.enabled != nil && time.Since(c.loadedAt) < c.ttl
+	_, ok := c.enabled[city]
+	c.mu.RUnlock()
+	if fresh {
+		return ok, nil
+	}
+
+	if err := c.refresh(ctx); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok = c.enabled[city]
+	return ok, nil
+}
+
+func (c *CityCatalog) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = nil
+}
+
+func (c *CityCatalog) refresh(ctx context.Context) error {
+	cities, err := c.repo.ListCities(ctx)
+	if err != nil {
+		return err
+	}
+
+	enabled := make(map[string]struct{}, len(cities))
+	for _, city := range cities {
+		if city.Enabled {
+			enabled[city.Name] = struct{}{}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.enabled = enabled
+	c.loadedAt = time.Now()
+	return nil
+}
+
+type CityService struct {
+	repo    repository.CityRepository
+	catalog *CityCatalog
+}
+
+func NewCityService(repo repository.CityRepository, catalog *CityCatalog) *CityService {
+	return &CityService{repo: repo, catalog: catalog}
+}
+
+func (s *CityService) ListCities(ctx context.Context) ([]domain.City, error) {
+	cities, err := s.repo.ListCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cities == nil {
+		cities = []domain.City{}
+	}
+	return cities, nil
+}
+
+func (s *CityService) CreateCity(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error) {
+	if err := authorize(principal.Role, authz.ManageCity, "только модератор может управлять городами"); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxCityNameLength {
+		return nil, errorWithMessage(ErrInvalidInput, "некорректное название города")
+	}
+
+	city, err := s.repo.CreateCity(ctx, name, enabled)
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, ErrCityAlreadyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.catalog.Invalidate()
+	return city, nil
+}
+
+func (s *CityService) SetCityEnabled(ctx context.Context, name string, enabled bool, principal domain.Principal) (*domain.City, error) {
+	if err := authorize(principal.Role, authz.ManageCity, "только модератор может управлять городами"); err != nil {
+		return nil, err
+	}
+
+	city, err := s.repo.SetCityEnabled(ctx, name, enabled)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errorWithMessage(ErrNotFound, "город не найден")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.catalog.Invalidate()
+	return city, nil
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+	"pvs/internal/service"
+)
+
+type mockCityRepo struct {
+	mock.Mock
+}
+
+func (m *mockCityRepo) ListCities(ctx context.Context) ([]domain.City, error) {
+	args := m.Called(ctx)
+	if cities := args.Get(0); cities != nil {
+		return cities.([]domain.City), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockCityRepo) CreateCity(ctx context.Context, name string, enabled bool) (*domain.City, error) {
+	args := m.Called(ctx, name, enabled)
+	if city := args.Get(0); city != nil {
+		return city.(*domain.City), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockCityRepo) SetCityEnabled(ctx context.Context, name string, enabled bool) (*domain.City, error) {
+	args := m.Called(ctx, name, enabled)
+	if city := args.Get(0); city != nil {
+		return city.(*domain.City), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func newCityCatalog() *service.CityCatalog {
+	repo := new(mockCityRepo)
+	repo.On("ListCities", mock.Anything).Return([]domain.City{
+		{Name: "Москва", Enabled: true},
+		{Name: "Санкт-Петербург", Enabled: true},
+		{Name: "Казань", Enabled: true},
+	}, nil)
+	return service.NewCityCatalog(repo, time.Minute)
+}
+
+func TestCityCatalog_CachesUntilInvalidated(t *testing.T) {
+	repo := new(mockCityRepo)
+	catalog := service.NewCityCatalog(repo, time.Hour)
+
+	repo.On("ListCities", mock.Anything).Return([]domain.City{
+		{Name: "Москва", Enabled: true},
+		{Name: "Казань", Enabled: false},
+	}, nil).Once()
+
+	ok, err := catalog.IsEnabled(context.Background(), "Москва")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = catalog.IsEnabled(context.Background(), "Казань")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	repo.AssertNumberOfCalls(t, "ListCities", 1)
+
+	repo.On("ListCities", mock.Anything).Return([]domain.City{
+		{Name: "Казань", Enabled: true},
+	}, nil).Once()
+	catalog.Invalidate()
+
+	ok, err = catalog.IsEnabled(context.Background(), "Казань")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	repo.AssertNumberOfCalls(t, "ListCities", 2)
+}
+
+func TestCityCatalog_RefreshError(t *testing.T) {
+	repo := new(mockCityRepo)
+	catalog := service.NewCityCatalog(repo, time.Hour)
+
+	repo.On("ListCities", mock.Anything).Return(nil, errors.New("db down"))
+
+	ok, err := catalog.IsEnabled(context.Background(), "Москва")
+	assert.False(t, ok)
+	assert.EqualError(t, err, "db down")
+}
+
+func TestCreateCity_InvalidatesCatalog(t *testing.T) {
+	repo := new(mockCityRepo)
+	catalog := service.NewCityCatalog(repo, time.Hour)
+	svc := service.NewCityService(repo, catalog)
+
+	repo.On("ListCities", mock.Anything).Return([]domain.City{}, nil).Once()
+	ok, _ := catalog.IsEnabled(context.Background(), "Новосибирск")
+	assert.False(t, ok)
+
+	repo.On("CreateCity", mock.Anything, "Новосибирск", true).Return(&domain.City{Name: "Новосибирск", Enabled: true}, nil)
+	repo.On("ListCities", mock.Anything).Return([]domain.City{{Name: "Новосибирск", Enabled: true}}, nil).Once()
+
+	city, err := svc.CreateCity(context.Background(), " Новосибирск ", true, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, "Новосибирск", city.Name)
+
+	ok, err = catalog.IsEnabled(context.Background(), "Новосибирск")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCreateCity_Forbidden(t *testing.T) {
+	repo := new(mockCityRepo)
+	svc := service.NewCityService(repo, service.NewCityCatalog(repo, time.Hour))
+
+	city, err := svc.CreateCity(context.Background(), "Новосибирск", true, employee)
+	assert.Nil(t, city)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "CreateCity", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateCity_AlreadyExists(t *testing.T) {
+	repo := new(mockCityRepo)
+	svc := service.NewCityService(repo, service.NewCityCatalog(repo, time.Hour))
+
+	repo.On("CreateCity", mock.Anything, "Москва", true).Return(nil, repository.ErrAlreadyExists)
+
+	_, err := svc.CreateCity(context.Background(), "Москва", true, moderator)
+	assert.ErrorIs(t, err, service.ErrCityAlreadyExists)
+}
+
+func TestSetCityEnabled_NotFound(t *testing.T) {
+	repo := new(mockCityRepo)
+	svc := service.NewCityService(repo, service.NewCityCatalog(repo, time.Hour))
+
+	repo.On("SetCityEnabled", mock.Anything, "Атлантида", false).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.SetCityEnabled(context.Background(), "Атлантида", false, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,47 @@
+package service
+
+import "pvs/internal/authz"
+
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	ErrInvalidInput         = &Error{Code: "invalid_input", Message: "некорректные данные"}
+	ErrForbidden            = &Error{Code: "forbidden", Message: "доступ запрещён"}
+	ErrNotFound             = &Error{Code: "not_found", Message: "объект не найден"}
+	ErrNoOpenReception      = &Error{Code: "no_open_reception", Message: "нет активной приёмки"}
+	ErrReceptionAlreadyOpen = &Error{Code: "reception_already_open", Message: "уже есть незакрытая приёмка"}
+	ErrCityNotAllowed       = &Error{Code: "city_not_allowed", Message: "город недоступен для регистрации"}
+	ErrCityAlreadyExists    = &Error{Code: "city_already_exists", Message: "такой город уже есть в справочнике"}
+	ErrUserAlreadyExists    = &Error{Code: "user_already_exists", Message: "пользователь с таким email уже существует"}
+	ErrInvalidCredentials   = &Error{Code: "invalid_credentials", Message: "неверный email или пароль"}
+	ErrInvalidRefreshToken  = &Error{Code: "invalid_refresh_token", Message: "refresh-токен недействителен"}
+	ErrWeakPassword         = &Error{Code: "weak_password", Message: "пароль не соответствует требованиям"}
+	ErrTooManyLoginAttempts = &Error{Code: "too_many_login_attempts", Message: "слишком много неудачных попыток входа, попробуйте позже"}
+
+	ErrUnknownProductType       = &Error{Code: "unknown_product_type", Message: "неизвестный тип товара"}
+	ErrProductTypeAlreadyExists = &Error{Code: "product_type_already_exists", Message: "такой тип товара уже существует"}
+	ErrProductTypeInUse         = &Error{Code: "product_type_in_use", Message: "тип товара используется в товарах"}
+)
+
+func errorWithMessage(kind *Error, message string) error {
+	return &Error{Code: kind.Code, Message: message}
+}
+
+func authorize(role string, action authz.Action, message string) error {
+	if !authz.Can(role, action) {
+		return errorWithMessage(ErrForbidden, message)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pvs/internal/repository"
+)
+
+type LoginThrottle struct {
+	MaxFailures int
+	// MaxIPFailures is the threshold for the client IP key. It is much
+	// higher than MaxFailures because many users may share one address.
+	MaxIPFailures int
+	Window        time.Duration
+	Lockout       time.Duration
+	MaxLockout    time.Duration
+}
+
+type LoginLimiter struct {
+	repo     repository.LoginAttemptRepository
+	throttle LoginThrottle
+}
+
+// loginKey is a throttled login attempt counter and the number of
+// failures after which it locks.
+type loginKey struct {
+	name        string
+	maxFailures int
+}
+
+func NewLoginLimiter(repo repository.LoginAttemptRepository, throttle LoginThrottle) *LoginLimiter {
+	return &LoginLimiter{repo: repo, throttle: throttle}
+}
+
+func (l *LoginLimiter) keys(email, clientIP string) []loginKey {
+	keys := []loginKey{{name: "email:" + email, maxFailures: l.throttle.MaxFailures}}
+	if clientIP != "" {
+		keys = append(keys, loginKey{name: "ip:" + clientIP, maxFailures: l.throttle.MaxIPFailures})
+	}
+	return keys
+}
+
+// Check reports whether the email and the client IP are currently locked.
+func (l *LoginLimiter) Check(ctx context.Context, email, clientIP string) (emailLocked, ipLocked bool, err error) {
+	now := time.Now()
+	for i, key := range l.keys(email, clientIP) {
+		attempt, err := l.repo.GetLoginAttempt(ctx, key.name)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, false, err
+		}
+		locked := attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil)
+		if i == 0 {
+			emailLocked = locked
+		} else {
+			ipLocked = locked
+		}
+	}
+	return emailLocked, ipLocked, nil
+}
+
+func (l *LoginLimiter) Fail(ctx context.Context, email, clientIP string) error {
+	for _, key := range l.keys(email, clientIP) {
+		attempt, err := l.repo.RecordLoginFailure(ctx, key.name, l.throttle.Window)
+		if err != nil {
+			return err
+		}
+		if attempt.Failures < key.maxFailures {
+			continue
+		}
+		lockout := l.lockoutFor(attempt.Failures - key.maxFailures)
+		if err := l.repo.LockLogin(ctx, key.name, time.Now().Add(lockout)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset clears the email key after a successful login. The IP key only
+// expires with the failure window.
+func (l *LoginLimiter) Reset(ctx context.Context, email string) error {
+	return l.repo.ResetLoginAttempts(ctx, l.keys(email, "")[0].name)
+}
+
+// PruneStale deletes counters that are outside the failure window and no
+// longer locked.
+func (l *LoginLimiter) PruneStale(ctx context.Context) error {
+	return l.repo.DeleteStale(ctx, l.throttle.Window)
+}
+
+// lockoutFor doubles the base lockout for every failure past the threshold.
+func (l *LoginLimiter) lockoutFor(extraFailures int) time.Duration {
+	lockout := l.throttle.Lockout
+	for i := 0; i < extraFailures && lockout < l.throttle.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return min(lockout, l.throttle.MaxLockout)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+const maxPasswordBytes = 72
+
+type PasswordPolicy struct {
+	MinLength     int
+	RequireLetter bool
+	RequireDigit  bool
+}
+
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return errorWithMessage(ErrWeakPassword, fmt.Sprintf("пароль должен быть не короче %d символов", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		return errorWithMessage(ErrWeakPassword, fmt.Sprintf("пароль должен быть не длиннее %d байт", maxPasswordBytes))
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if p.RequireLetter && !hasLetter {
+		return errorWithMessage(ErrWeakPassword, "пароль должен содержать хотя бы одну букву")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errorWithMessage(ErrWeakPassword, "пароль должен содержать хотя бы одну цифру")
+	}
+	return nil
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// maxEmailLength is the longest address SMTP allows (RFC 5321).
+const maxEmailLength = 254
+
+func validateEmail(email string) error {
+	if len(email) > maxEmailLength {
+		return errorWithMessage(ErrInvalidInput, "некорректный email")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return errorWithMessage(ErrInvalidInput, "некорректный email")
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return errorWithMessage(ErrInvalidInput, "некорректный email")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/service"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := service.PasswordPolicy{MinLength: 8, RequireLetter: true, RequireDigit: true}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"valid", "securepass1", true},
+		{"cyrillic letters count", "пароль123", true},
+		{"too short", "abc123", false},
+		{"no digit", "securepass", false},
+		{"no letter", "1234567890", false},
+		{"over bcrypt limit", strings.Repeat("a1", 40), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, service.ErrWeakPassword)
+			}
+		})
+	}
+}
@@ -5,41 +5,99 @@ import (
 	"errors"
 
 	"github.com/google/uuid"
+	"pvs/internal/authz"
 	"pvs/internal/domain"
+	"pvs/internal/metrics"
 	"pvs/internal/repository"
 )
 
 type ProductService struct {
-	productRepo   repository.ProductRepository
-	receptionRepo repository.ReceptionRepository
+	productRepo     repository.ProductRepository
+	receptionRepo   repository.ReceptionRepository
+	pvzRepo         repository.PVZRepository
+	productTypeRepo repository.ProductTypeRepository
+	staff           repository.StaffRepository
+	tx              repository.Transactor
 }
 
-func NewProductService(productRepo repository.ProductRepository, receptionRepo repository.ReceptionRepository) *ProductService {
-	return &ProductService{productRepo: productRepo, receptionRepo: receptionRepo}
+func NewProductService(
+	productRepo repository.ProductRepository,
+	receptionRepo repository.ReceptionRepository,
+	pvzRepo repository.PVZRepository,
+	productTypeRepo repository.ProductTypeRepository,
+	staff repository.StaffRepository,
+	tx repository.Transactor,
+) *ProductService {
+	return &ProductService{
+		productRepo:     productRepo,
+		receptionRepo:   receptionRepo,
+		pvzRepo:         pvzRepo,
+		productTypeRepo: productTypeRepo,
+		staff:           staff,
+		tx:              tx,
+	}
 }
 
-func (s *ProductService) AddProduct(ctx context.Context, pvzID uuid.UUID, productType string, role string) (*domain.Product, error) {
-	if role != "employee" {
-		return nil, errors.New("только сотрудники могут добавлять товары")
+func (s *ProductService) AddProduct(ctx context.Context, pvzID uuid.UUID, productType string, principal domain.Principal) (*domain.Product, error) {
+	if err := authorize(principal.Role, authz.AddProduct, "только сотрудники могут добавлять товары"); err != nil {
+		return nil, err
 	}
 
-	reception, err := s.receptionRepo.GetOpenReception(ctx, pvzID)
+	known, err := s.productTypeRepo.ProductTypeExists(ctx, productType)
 	if err != nil {
-		return nil, errors.New("нет активной приёмки")
+		return nil, err
 	}
+	if !known {
+		return nil, errorWithMessage(ErrUnknownProductType, "неизвестный тип товара: "+productType)
+	}
+
+	var product *domain.Product
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		reception, err := s.lockOpenReception(ctx, pvzID, principal)
+		if err != nil {
+			return err
+		}
 
-	return s.productRepo.AddProduct(ctx, reception.ID, productType)
+		product, err = s.productRepo.AddProduct(ctx, reception.ID, productType, principal.UserID)
+		// the type may have been deleted or renamed since the check above
+		if errors.Is(err, repository.ErrBrokenReference) {
+			return errorWithMessage(ErrUnknownProductType, "неизвестный тип товара: "+productType)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ProductsAddedTotal.Inc()
+	return product, nil
 }
 
-func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, role string) error {
-	if role != "employee" {
-		return errors.New("только сотрудники могут удалять товары")
+func (s *ProductService) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) error {
+	if err := authorize(principal.Role, authz.DeleteProduct, "только сотрудники могут удалять товары"); err != nil {
+		return err
 	}
 
-	reception, err := s.receptionRepo.GetOpenReception(ctx, pvzID)
-	if err != nil {
-		return errors.New("нет активной приёмки")
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		reception, err := s.lockOpenReception(ctx, pvzID, principal)
+		if err != nil {
+			return err
+		}
+
+		return s.productRepo.DeleteLastProduct(ctx, reception.ID)
+	})
+}
+
+func (s *ProductService) lockOpenReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	if err := lockPVZ(ctx, s.pvzRepo, pvzID); err != nil {
+		return nil, err
+	}
+	if err := requireStaff(ctx, s.staff, pvzID, principal); err != nil {
+		return nil, err
 	}
 
-	return s.productRepo.DeleteLastProduct(ctx, reception.ID)
+	reception, err := s.receptionRepo.GetOpenReception(ctx, pvzID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoOpenReception
+	}
+	return reception, err
 }
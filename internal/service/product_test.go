@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 	"pvs/internal/service"
 )
 
@@ -16,8 +17,8 @@ type mockProductRepo struct {
 	mock.Mock
 }
 
-func (m *mockProductRepo) AddProduct(ctx context.Context, receptionID uuid.UUID, productType string) (*domain.Product, error) {
-	args := m.Called(ctx, receptionID, productType)
+func (m *mockProductRepo) AddProduct(ctx context.Context, receptionID uuid.UUID, productType string, createdBy uuid.UUID) (*domain.Product, error) {
+	args := m.Called(ctx, receptionID, productType, createdBy)
 	if prod := args.Get(0); prod != nil {
 		return prod.(*domain.Product), args.Error(1)
 	}
@@ -34,20 +35,29 @@ func (m *mockProductRepo) GetProductsByReception(ctx context.Context, receptionI
 	return args.Get(0).([]domain.Product), args.Error(1)
 }
 
+func (m *mockProductRepo) ListByReceptionIDs(ctx context.Context, receptionIDs []uuid.UUID) ([]domain.Product, error) {
+	args := m.Called(ctx, receptionIDs)
+	return args.Get(0).([]domain.Product), args.Error(1)
+}
+
 func TestAddProduct_Success(t *testing.T) {
 	productRepo := new(mockProductRepo)
 	receptionRepo := new(mockReceptionRepo)
-	svc := service.NewProductService(productRepo, receptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, mock.Anything).Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
 
 	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
 	receptionID := uuid.New()
 	productType := "electronics"
 	expectedProduct := &domain.Product{ID: uuid.New(), Type: productType}
 
 	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(&domain.Reception{ID: receptionID}, nil)
-	productRepo.On("AddProduct", mock.Anything, receptionID, productType).Return(expectedProduct, nil)
+	productRepo.On("AddProduct", mock.Anything, receptionID, productType, employee.UserID).Return(expectedProduct, nil)
 
-	result, err := svc.AddProduct(context.Background(), pvzID, productType, "employee")
+	result, err := svc.AddProduct(context.Background(), pvzID, productType, employee)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProduct, result)
 
@@ -56,41 +66,71 @@ func TestAddProduct_Success(t *testing.T) {
 }
 
 func TestAddProduct_Unauthorized(t *testing.T) {
-	svc := service.NewProductService(nil, nil)
+	svc := service.NewProductService(nil, nil, nil, nil, nil, nil)
 
-	result, err := svc.AddProduct(context.Background(), uuid.New(), "toys", "moderator")
+	result, err := svc.AddProduct(context.Background(), uuid.New(), "toys", moderator)
 	assert.Nil(t, result)
 	assert.EqualError(t, err, "только сотрудники могут добавлять товары")
+	assert.ErrorIs(t, err, service.ErrForbidden)
 }
 
 func TestAddProduct_NoReception(t *testing.T) {
 	productRepo := new(mockProductRepo)
 	receptionRepo := new(mockReceptionRepo)
-	svc := service.NewProductService(productRepo, receptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, mock.Anything).Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
 
 	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
 
-	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, errors.New("not found"))
+	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, repository.ErrNotFound)
 
-	result, err := svc.AddProduct(context.Background(), pvzID, "books", "employee")
+	result, err := svc.AddProduct(context.Background(), pvzID, "books", employee)
 	assert.Nil(t, result)
 	assert.EqualError(t, err, "нет активной приёмки")
+	assert.ErrorIs(t, err, service.ErrNoOpenReception)
 
 	receptionRepo.AssertExpectations(t)
 }
 
+func TestAddProduct_ReceptionLookupError(t *testing.T) {
+	productRepo := new(mockProductRepo)
+	receptionRepo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, mock.Anything).Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	dbErr := errors.New("db down")
+
+	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, dbErr)
+
+	result, err := svc.AddProduct(context.Background(), pvzID, "обувь", employee)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, dbErr)
+	assert.NotErrorIs(t, err, service.ErrNoOpenReception)
+}
+
 func TestDeleteLastProduct_Success(t *testing.T) {
 	productRepo := new(mockProductRepo)
 	receptionRepo := new(mockReceptionRepo)
-	svc := service.NewProductService(productRepo, receptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, mock.Anything).Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
 
 	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
 	receptionID := uuid.New()
 
 	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(&domain.Reception{ID: receptionID}, nil)
 	productRepo.On("DeleteLastProduct", mock.Anything, receptionID).Return(nil)
 
-	err := svc.DeleteLastProduct(context.Background(), pvzID, "employee")
+	err := svc.DeleteLastProduct(context.Background(), pvzID, employee)
 	assert.NoError(t, err)
 
 	receptionRepo.AssertExpectations(t)
@@ -98,23 +138,115 @@ func TestDeleteLastProduct_Success(t *testing.T) {
 }
 
 func TestDeleteLastProduct_Unauthorized(t *testing.T) {
-	svc := service.NewProductService(nil, nil)
+	svc := service.NewProductService(nil, nil, nil, nil, nil, nil)
 
-	err := svc.DeleteLastProduct(context.Background(), uuid.New(), "moderator")
+	err := svc.DeleteLastProduct(context.Background(), uuid.New(), moderator)
 	assert.EqualError(t, err, "только сотрудники могут удалять товары")
 }
 
 func TestDeleteLastProduct_NoReception(t *testing.T) {
 	productRepo := new(mockProductRepo)
 	receptionRepo := new(mockReceptionRepo)
-	svc := service.NewProductService(productRepo, receptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, mock.Anything).Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
 
 	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
 
-	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, errors.New("none"))
+	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, repository.ErrNotFound)
 
-	err := svc.DeleteLastProduct(context.Background(), pvzID, "employee")
+	err := svc.DeleteLastProduct(context.Background(), pvzID, employee)
 	assert.EqualError(t, err, "нет активной приёмки")
 
 	receptionRepo.AssertExpectations(t)
 }
+
+func TestAddProduct_RunsInTransaction(t *testing.T) {
+	productRepo := new(mockProductRepo)
+	receptionRepo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, "обувь").Return(true, nil)
+	tx := new(fakeTransactor)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), tx)
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(repository.ErrNotFound)
+
+	result, err := svc.AddProduct(context.Background(), pvzID, "обувь", employee)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.Equal(t, 1, tx.calls)
+	receptionRepo.AssertNotCalled(t, "GetOpenReception", mock.Anything, mock.Anything)
+}
+
+func TestAddProduct_UnknownType(t *testing.T) {
+	productTypeRepo := new(mockProductTypeRepo)
+	tx := new(fakeTransactor)
+	svc := service.NewProductService(nil, nil, nil, productTypeRepo, nil, tx)
+
+	productTypeRepo.On("ProductTypeExists", mock.Anything, "книги").Return(false, nil)
+
+	result, err := svc.AddProduct(context.Background(), uuid.New(), "книги", employee)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrUnknownProductType)
+	assert.Contains(t, err.Error(), "книги")
+	assert.Equal(t, 0, tx.calls)
+}
+
+func TestAddProduct_TypeRemovedConcurrently(t *testing.T) {
+	productRepo := new(mockProductRepo)
+	receptionRepo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, "книги").Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, assignedStaff(), new(fakeTransactor))
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	receptionRepo.On("GetOpenReception", mock.Anything, pvzID).Return(&domain.Reception{ID: receptionID}, nil)
+	productRepo.On("AddProduct", mock.Anything, receptionID, "книги", employee.UserID).Return(nil, repository.ErrBrokenReference)
+
+	result, err := svc.AddProduct(context.Background(), pvzID, "книги", employee)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrUnknownProductType)
+}
+
+func TestAddProduct_NotAssignedToPVZ(t *testing.T) {
+	productRepo := new(mockProductRepo)
+	receptionRepo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productTypeRepo := new(mockProductTypeRepo)
+	staffRepo := new(mockStaffRepo)
+	productTypeRepo.On("ProductTypeExists", mock.Anything, "обувь").Return(true, nil)
+	svc := service.NewProductService(productRepo, receptionRepo, pvzRepo, productTypeRepo, staffRepo, new(fakeTransactor))
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(false, nil)
+
+	result, err := svc.AddProduct(context.Background(), pvzID, "обувь", employee)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.EqualError(t, err, "сотрудник не назначен на этот ПВЗ")
+	receptionRepo.AssertNotCalled(t, "GetOpenReception", mock.Anything, mock.Anything)
+	productRepo.AssertNotCalled(t, "AddProduct", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteLastProduct_NotAssignedToPVZ(t *testing.T) {
+	productRepo := new(mockProductRepo)
+	pvzRepo := new(mockPVZRepo)
+	staffRepo := new(mockStaffRepo)
+	svc := service.NewProductService(productRepo, new(mockReceptionRepo), pvzRepo, nil, staffRepo, new(fakeTransactor))
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(false, nil)
+
+	err := svc.DeleteLastProduct(context.Background(), pvzID, employee)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	productRepo.AssertNotCalled(t, "DeleteLastProduct", mock.Anything, mock.Anything)
+}
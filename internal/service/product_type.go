@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+const maxProductTypeLength = 64
+
+type ProductTypeService struct {
+	repo repository.ProductTypeRepository
+}
+
+func NewProductTypeService(repo repository.ProductTypeRepository) *ProductTypeService {
+	return &ProductTypeService{repo: repo}
+}
+
+func (s *ProductTypeService) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	types, err := s.repo.ListProductTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if types == nil {
+		types = []domain.ProductType{}
+	}
+	return types, nil
+}
+
+func (s *ProductTypeService) CreateProductType(ctx context.Context, name string, principal domain.Principal) (*domain.ProductType, error) {
+	if err := authorize(principal.Role, authz.ManageProductType, "только модератор может управлять типами товаров"); err != nil {
+		return nil, err
+	}
+
+	name, ok := normalizeProductType(name)
+	if !ok {
+		return nil, errorWithMessage(ErrInvalidInput, "некорректное название типа товара")
+	}
+
+	pt, err := s.repo.CreateProductType(ctx, name)
+	if errors.Is(err, repository.ErrAlreadyExists) {
+		return nil, ErrProductTypeAlreadyExists
+	}
+	return pt, err
+}
+
+// RenameProductType changes the name of a type; existing products are
+// renamed with it by the database.
+func (s *ProductTypeService) RenameProductType(ctx context.Context, name, newName string, principal domain.Principal) (*domain.ProductType, error) {
+	if err := authorize(principal.Role, authz.ManageProductType, "только модератор может управлять типами товаров"); err != nil {
+		return nil, err
+	}
+
+	newName, ok := normalizeProductType(newName)
+	if !ok {
+		return nil, errorWithMessage(ErrInvalidInput, "некорректное название типа товара")
+	}
+
+	pt, err := s.repo.RenameProductType(ctx, name, newName)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return nil, errorWithMessage(ErrNotFound, "тип товара не найден")
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return nil, ErrProductTypeAlreadyExists
+	}
+	return pt, err
+}
+
+func (s *ProductTypeService) DeleteProductType(ctx context.Context, name string, principal domain.Principal) error {
+	if err := authorize(principal.Role, authz.ManageProductType, "только модератор может управлять типами товаров"); err != nil {
+		return err
+	}
+
+	err := s.repo.DeleteProductType(ctx, name)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return errorWithMessage(ErrNotFound, "тип товара не найден")
+	case errors.Is(err, repository.ErrInUse):
+		return ErrProductTypeInUse
+	}
+	return err
+}
+
+func normalizeProductType(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	return name, name != "" && utf8.RuneCountInString(name) <= maxProductTypeLength
+}
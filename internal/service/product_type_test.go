@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+	"pvs/internal/service"
+)
+
+type mockProductTypeRepo struct {
+	mock.Mock
+}
+
+func (m *mockProductTypeRepo) ListProductTypes(ctx context.Context) ([]domain.ProductType, error) {
+	args := m.Called(ctx)
+	if types := args.Get(0); types != nil {
+		return types.([]domain.ProductType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockProductTypeRepo) CreateProductType(ctx context.Context, name string) (*domain.ProductType, error) {
+	args := m.Called(ctx, name)
+	if pt := args.Get(0); pt != nil {
+		return pt.(*domain.ProductType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockProductTypeRepo) RenameProductType(ctx context.Context, name, newName string) (*domain.ProductType, error) {
+	args := m.Called(ctx, name, newName)
+	if pt := args.Get(0); pt != nil {
+		return pt.(*domain.ProductType), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockProductTypeRepo) DeleteProductType(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *mockProductTypeRepo) ProductTypeExists(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestListProductTypes_EmptyCatalog(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	repo.On("ListProductTypes", mock.Anything).Return(nil, nil)
+
+	types, err := svc.ListProductTypes(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, types)
+	assert.Empty(t, types)
+}
+
+func TestCreateProductType_Success(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	expected := &domain.ProductType{Name: "книги"}
+	repo.On("CreateProductType", mock.Anything, "книги").Return(expected, nil)
+
+	pt, err := svc.CreateProductType(context.Background(), "  книги ", moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pt)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateProductType_NotModerator(t *testing.T) {
+	svc := service.NewProductTypeService(nil)
+
+	pt, err := svc.CreateProductType(context.Background(), "книги", employee)
+	assert.Nil(t, pt)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestCreateProductType_EmptyName(t *testing.T) {
+	svc := service.NewProductTypeService(nil)
+
+	pt, err := svc.CreateProductType(context.Background(), "   ", moderator)
+	assert.Nil(t, pt)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+}
+
+func TestCreateProductType_Duplicate(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	repo.On("CreateProductType", mock.Anything, "обувь").Return(nil, repository.ErrAlreadyExists)
+
+	pt, err := svc.CreateProductType(context.Background(), "обувь", moderator)
+	assert.Nil(t, pt)
+	assert.ErrorIs(t, err, service.ErrProductTypeAlreadyExists)
+}
+
+func TestDeleteProductType_InUse(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	repo.On("DeleteProductType", mock.Anything, "обувь").Return(repository.ErrInUse)
+
+	err := svc.DeleteProductType(context.Background(), "обувь", moderator)
+	assert.ErrorIs(t, err, service.ErrProductTypeInUse)
+}
+
+func TestDeleteProductType_NotFound(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	repo.On("DeleteProductType", mock.Anything, "игрушки").Return(repository.ErrNotFound)
+
+	err := svc.DeleteProductType(context.Background(), "игрушки", moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestRenameProductType_Success(t *testing.T) {
+	repo := new(mockProductTypeRepo)
+	svc := service.NewProductTypeService(repo)
+
+	expected := &domain.ProductType{Name: "книги"}
+	repo.On("RenameProductType", mock.Anything, "кники", "книги").Return(expected, nil)
+
+	pt, err := svc.RenameProductType(context.Background(), "кники", " книги ", moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pt)
+	repo.AssertExpectations(t)
+}
+
+func TestRenameProductType_NotModerator(t *testing.T) {
+	svc := service.NewProductTypeService(nil)
+
+	pt, err := svc.RenameProductType(context.Background(), "обувь", "ботинки", employee)
+	assert.Nil(t, pt)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestRenameProductType_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		want    error
+	}{
+		{"not found", repository.ErrNotFound, service.ErrNotFound},
+		{"name taken", repository.ErrAlreadyExists, service.ErrProductTypeAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockProductTypeRepo)
+			svc := service.NewProductTypeService(repo)
+			repo.On("RenameProductType", mock.Anything, "обувь", "одежда").Return(nil, tt.repoErr)
+
+			pt, err := svc.RenameProductType(context.Background(), "обувь", "одежда", moderator)
+			assert.Nil(t, pt)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -2,29 +2,148 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"pvs/internal/authz"
 	"pvs/internal/domain"
+	"pvs/internal/metrics"
 	"pvs/internal/repository"
 )
 
+const MaxPageLimit = 30
+
 type PVZService struct {
-	repo repository.PVZRepository
+	repo          repository.PVZRepository
+	receptionRepo repository.ReceptionRepository
+	productRepo   repository.ProductRepository
+	cities        *CityCatalog
+}
+
+func NewPVSService(
+	repo repository.PVZRepository,
+	receptionRepo repository.ReceptionRepository,
+	productRepo repository.ProductRepository,
+	cities *CityCatalog,
+) *PVZService {
+	return &PVZService{repo: repo, receptionRepo: receptionRepo, productRepo: productRepo, cities: cities}
 }
 
-func NewPVSService(repo repository.PVZRepository) *PVZService {
-	return &PVZService{repo: repo}
+func (s *PVZService) CreatePVZ(ctx context.Context, city string, principal domain.Principal) (*domain.PVZ, error) {
+	if err := authorize(principal.Role, authz.CreatePVZ, "только модератор может создавать ПВЗ"); err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.cities.IsEnabled(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCityNotAllowed
+	}
+	pvz, err := s.repo.CreatePVZ(ctx, city)
+	if err != nil {
+		return nil, err
+	}
+	metrics.PVZCreatedTotal.Inc()
+	return pvz, nil
 }
 
-func (s *PVZService) CreatePVZ(ctx context.Context, city string) (*domain.PVZ, error) {
-	allowed := map[string]struct{}{"Москва": {}, "Санкт-Петербург": {}, "Казань": {}}
-	if _, ok := allowed[city]; !ok {
-		return nil, errors.New("город недоступен для регистрации")
+func (s *PVZService) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) (*domain.PVZPage, error) {
+	pvzs, err := s.ListPVZPage(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
-	return s.repo.CreatePVZ(ctx, city)
+
+	page := &domain.PVZPage{Total: pvzs.Total, HasMore: pvzs.HasMore, NextCursor: pvzs.NextCursor}
+	page.Items, err = s.withReceptions(ctx, pvzs.Items, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
 }
 
-func (s *PVZService) ListPVZWithFilter(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]domain.PVZ, error) {
-	return s.repo.ListPVZWithFilter(ctx, startDate, endDate, page, limit)
+// ListPVZPage returns the same page as ListPVZWithFilter without loading
+// receptions and products.
+func (s *PVZService) ListPVZPage(ctx context.Context, filter domain.PVZFilter) (*domain.Page[domain.PVZ], error) {
+	if filter.Limit < 1 || filter.Limit > MaxPageLimit {
+		return nil, errorWithMessage(ErrInvalidInput, fmt.Sprintf("limit должен быть от 1 до %d", MaxPageLimit))
+	}
+	if filter.After == nil && filter.Page < 1 {
+		return nil, errorWithMessage(ErrInvalidInput, "page должен быть не меньше 1")
+	}
+
+	pvzs, err := s.repo.ListPVZWithFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountPVZ(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.Page[domain.PVZ]{Total: total}
+	if len(pvzs) > filter.Limit {
+		page.HasMore = true
+		pvzs = pvzs[:filter.Limit]
+		last := pvzs[len(pvzs)-1]
+		page.NextCursor = domain.PVZCursor{RegistrationDate: last.RegistrationDate, ID: last.ID}.Encode()
+	}
+	page.Items = pvzs
+	return page, nil
+}
+
+func (s *PVZService) withReceptions(ctx context.Context, pvzs []domain.PVZ, startDate, endDate *time.Time) ([]domain.PVZWithReceptions, error) {
+	result := make([]domain.PVZWithReceptions, 0, len(pvzs))
+	if len(pvzs) == 0 {
+		return result, nil
+	}
+
+	pvzIDs := make([]uuid.UUID, 0, len(pvzs))
+	for _, p := range pvzs {
+		pvzIDs = append(pvzIDs, p.ID)
+	}
+
+	receptions, err := s.receptionRepo.ListByPVZIDs(ctx, pvzIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	productsByReception := make(map[uuid.UUID][]domain.Product, len(receptions))
+	if len(receptions) > 0 {
+		receptionIDs := make([]uuid.UUID, 0, len(receptions))
+		for _, rec := range receptions {
+			receptionIDs = append(receptionIDs, rec.ID)
+		}
+
+		products, err := s.productRepo.ListByReceptionIDs(ctx, receptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			productsByReception[p.ReceptionID] = append(productsByReception[p.ReceptionID], p)
+		}
+	}
+
+	receptionsByPVZ := make(map[uuid.UUID][]domain.ReceptionWithProducts, len(pvzs))
+	for _, rec := range receptions {
+		products := productsByReception[rec.ID]
+		if products == nil {
+			products = []domain.Product{}
+		}
+		receptionsByPVZ[rec.PVZID] = append(receptionsByPVZ[rec.PVZID], domain.ReceptionWithProducts{
+			Reception: rec,
+			Products:  products,
+		})
+	}
+
+	for _, p := range pvzs {
+		recs := receptionsByPVZ[p.ID]
+		if recs == nil {
+			recs = []domain.ReceptionWithProducts{}
+		}
+		result = append(result, domain.PVZWithReceptions{PVZ: p, Receptions: recs})
+	}
+	return result, nil
 }
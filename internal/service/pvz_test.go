@@ -5,9 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"pvs/internal/domain"
+	"pvs/internal/metrics"
 	"pvs/internal/service"
 )
 
@@ -23,53 +26,209 @@ func (m *mockPVZRepo) CreatePVZ(ctx context.Context, city string) (*domain.PVZ,
 	return nil, args.Error(1)
 }
 
-func (m *mockPVZRepo) ListPVZWithFilter(ctx context.Context, startDate, endDate *time.Time, page, limit int) ([]domain.PVZ, error) {
-	args := m.Called(ctx, startDate, endDate, page, limit)
+func (m *mockPVZRepo) GetPVZ(ctx context.Context, id uuid.UUID) (*domain.PVZ, error) {
+	args := m.Called(ctx, id)
+	if pvz := args.Get(0); pvz != nil {
+		return pvz.(*domain.PVZ), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockPVZRepo) LockPVZ(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockPVZRepo) CountPVZ(ctx context.Context, filter domain.PVZFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockPVZRepo) ListPVZWithFilter(ctx context.Context, filter domain.PVZFilter) ([]domain.PVZ, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).([]domain.PVZ), args.Error(1)
 }
 
 func TestCreatePVZ_AllowedCity(t *testing.T) {
 	repo := new(mockPVZRepo)
-	svc := service.NewPVSService(repo)
+	svc := service.NewPVSService(repo, nil, nil, newCityCatalog())
 
 	city := "Москва"
 	expected := &domain.PVZ{City: city}
 
 	repo.On("CreatePVZ", mock.Anything, city).Return(expected, nil)
+	before := testutil.ToFloat64(metrics.PVZCreatedTotal)
 
-	pvz, err := svc.CreatePVZ(context.Background(), city)
+	pvz, err := svc.CreatePVZ(context.Background(), city, moderator)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, pvz)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.PVZCreatedTotal))
 	repo.AssertExpectations(t)
 }
 
 func TestCreatePVZ_DisallowedCity(t *testing.T) {
 	repo := new(mockPVZRepo)
-	svc := service.NewPVSService(repo)
+	svc := service.NewPVSService(repo, nil, nil, newCityCatalog())
 
-	pvz, err := svc.CreatePVZ(context.Background(), "Новосибирск")
+	pvz, err := svc.CreatePVZ(context.Background(), "Новосибирск", moderator)
 	assert.Nil(t, pvz)
 	assert.EqualError(t, err, "город недоступен для регистрации")
+	assert.ErrorIs(t, err, service.ErrCityNotAllowed)
+}
+
+func TestCreatePVZ_OnlyModerator(t *testing.T) {
+	repo := new(mockPVZRepo)
+	svc := service.NewPVSService(repo, nil, nil, nil)
+
+	pvz, err := svc.CreatePVZ(context.Background(), "Москва", employee)
+	assert.Nil(t, pvz)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "CreatePVZ", mock.Anything, mock.Anything)
 }
 
 func TestListPVZWithFilter(t *testing.T) {
 	repo := new(mockPVZRepo)
-	svc := service.NewPVSService(repo)
+	receptionRepo := new(mockReceptionRepo)
+	productRepo := new(mockProductRepo)
+	svc := service.NewPVSService(repo, receptionRepo, productRepo, newCityCatalog())
 
 	start := time.Now().Add(-24 * time.Hour)
 	end := time.Now()
 	page := 1
 	limit := 10
 
-	expected := []domain.PVZ{
-		{City: "Москва"},
-		{City: "Казань"},
-	}
+	moscow := domain.PVZ{ID: uuid.New(), City: "Москва"}
+	kazan := domain.PVZ{ID: uuid.New(), City: "Казань"}
+	reception := domain.Reception{ID: uuid.New(), PVZID: moscow.ID, Status: "in_progress"}
+	product := domain.Product{ID: uuid.New(), Type: "обувь", ReceptionID: reception.ID}
+
+	repo.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{StartDate: &start, EndDate: &end, Page: page, Limit: limit}).
+		Return([]domain.PVZ{moscow, kazan}, nil)
+	repo.On("CountPVZ", mock.Anything, mock.Anything).Return(2, nil)
+	receptionRepo.On("ListByPVZIDs", mock.Anything, []uuid.UUID{moscow.ID, kazan.ID}, &start, &end).
+		Return([]domain.Reception{reception}, nil)
+	productRepo.On("ListByReceptionIDs", mock.Anything, []uuid.UUID{reception.ID}).
+		Return([]domain.Product{product}, nil)
+
+	result, err := svc.ListPVZWithFilter(context.Background(), domain.PVZFilter{StartDate: &start, EndDate: &end, Page: page, Limit: limit})
+	assert.NoError(t, err)
+	assert.Empty(t, result.NextCursor)
+	assert.Equal(t, 2, result.Total)
+	assert.False(t, result.HasMore)
+	assert.Equal(t, []domain.PVZWithReceptions{
+		{
+			PVZ: moscow,
+			Receptions: []domain.ReceptionWithProducts{
+				{Reception: reception, Products: []domain.Product{product}},
+			},
+		},
+		{PVZ: kazan, Receptions: []domain.ReceptionWithProducts{}},
+	}, result.Items)
+	repo.AssertExpectations(t)
+	receptionRepo.AssertExpectations(t)
+	productRepo.AssertExpectations(t)
+}
+
+func TestListPVZPage_SkipsReceptions(t *testing.T) {
+	repo := new(mockPVZRepo)
+	receptionRepo := new(mockReceptionRepo)
+	productRepo := new(mockProductRepo)
+	svc := service.NewPVSService(repo, receptionRepo, productRepo, newCityCatalog())
+
+	first := domain.PVZ{ID: uuid.New(), City: "Москва", RegistrationDate: time.Now()}
+	second := domain.PVZ{ID: uuid.New(), City: "Казань", RegistrationDate: time.Now().Add(-time.Hour)}
+	filter := domain.PVZFilter{Page: 1, Limit: 1}
+	repo.On("ListPVZWithFilter", mock.Anything, filter).Return([]domain.PVZ{first, second}, nil)
+	repo.On("CountPVZ", mock.Anything, filter).Return(2, nil)
+
+	result, err := svc.ListPVZPage(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.PVZ{first}, result.Items)
+	assert.Equal(t, 2, result.Total)
+	assert.True(t, result.HasMore)
+	assert.NotEmpty(t, result.NextCursor)
+	receptionRepo.AssertNotCalled(t, "ListByPVZIDs")
+	productRepo.AssertNotCalled(t, "ListByReceptionIDs")
+}
+
+func TestListPVZWithFilter_Empty(t *testing.T) {
+	repo := new(mockPVZRepo)
+	svc := service.NewPVSService(repo, nil, nil, newCityCatalog())
+
+	repo.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 1, Limit: 10}).Return([]domain.PVZ{}, nil)
+	repo.On("CountPVZ", mock.Anything, mock.Anything).Return(0, nil)
+
+	result, err := svc.ListPVZWithFilter(context.Background(), domain.PVZFilter{Page: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Items)
+	assert.Empty(t, result.Items)
+	assert.Empty(t, result.NextCursor)
+	repo.AssertExpectations(t)
+}
+
+func TestListPVZWithFilter_NextCursor(t *testing.T) {
+	repo := new(mockPVZRepo)
+	receptionRepo := new(mockReceptionRepo)
+	svc := service.NewPVSService(repo, receptionRepo, nil, newCityCatalog())
+
+	now := time.Now().UTC()
+	first := domain.PVZ{ID: uuid.New(), City: "Москва", RegistrationDate: now}
+	second := domain.PVZ{ID: uuid.New(), City: "Казань", RegistrationDate: now.Add(-time.Minute)}
+	extra := domain.PVZ{ID: uuid.New(), City: "Москва", RegistrationDate: now.Add(-2 * time.Minute)}
+	after := &domain.PVZCursor{RegistrationDate: now.Add(time.Hour), ID: uuid.New()}
+
+	repo.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Limit: 2, After: after}).
+		Return([]domain.PVZ{first, second, extra}, nil)
+	repo.On("CountPVZ", mock.Anything, mock.Anything).Return(7, nil)
+	receptionRepo.On("ListByPVZIDs", mock.Anything, []uuid.UUID{first.ID, second.ID}, (*time.Time)(nil), (*time.Time)(nil)).
+		Return([]domain.Reception{}, nil)
+
+	result, err := svc.ListPVZWithFilter(context.Background(), domain.PVZFilter{Limit: 2, After: after})
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+	assert.True(t, result.HasMore)
+	assert.Equal(t, 7, result.Total)
 
-	repo.On("ListPVZWithFilter", mock.Anything, &start, &end, page, limit).Return(expected, nil)
+	cursor, err := domain.DecodePVZCursor(result.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, second.ID, cursor.ID)
+	assert.True(t, second.RegistrationDate.Equal(cursor.RegistrationDate))
+}
 
-	result, err := svc.ListPVZWithFilter(context.Background(), &start, &end, page, limit)
+func TestListPVZWithFilter_InvalidPaging(t *testing.T) {
+	repo := new(mockPVZRepo)
+	svc := service.NewPVSService(repo, nil, nil, newCityCatalog())
+
+	for _, filter := range []domain.PVZFilter{
+		{Page: 1, Limit: 0},
+		{Page: 1, Limit: service.MaxPageLimit + 1},
+		{Page: 1, Limit: -5},
+		{Page: 0, Limit: 10},
+	} {
+		result, err := svc.ListPVZWithFilter(context.Background(), filter)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	}
+	repo.AssertNotCalled(t, "ListPVZWithFilter", mock.Anything, mock.Anything)
+}
+
+func TestListPVZWithFilter_LaterPageKeepsRequestedLimit(t *testing.T) {
+	repo := new(mockPVZRepo)
+	receptionRepo := new(mockReceptionRepo)
+	svc := service.NewPVSService(repo, receptionRepo, new(mockProductRepo), newCityCatalog())
+
+	third := domain.PVZ{ID: uuid.New(), City: "Москва"}
+	fourth := domain.PVZ{ID: uuid.New(), City: "Казань"}
+	repo.On("ListPVZWithFilter", mock.Anything, domain.PVZFilter{Page: 2, Limit: 2}).
+		Return([]domain.PVZ{third, fourth}, nil)
+	repo.On("CountPVZ", mock.Anything, mock.Anything).Return(4, nil)
+	receptionRepo.On("ListByPVZIDs", mock.Anything, []uuid.UUID{third.ID, fourth.ID}, (*time.Time)(nil), (*time.Time)(nil)).
+		Return([]domain.Reception{}, nil)
+
+	result, err := svc.ListPVZWithFilter(context.Background(), domain.PVZFilter{Page: 2, Limit: 2})
 	assert.NoError(t, err)
-	assert.Equal(t, expected, result)
+	assert.Len(t, result.Items, 2)
+	assert.Empty(t, result.NextCursor)
+	assert.False(t, result.HasMore)
 	repo.AssertExpectations(t)
 }
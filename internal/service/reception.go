@@ -3,34 +3,243 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
+	"pvs/internal/authz"
 	"pvs/internal/domain"
+	"pvs/internal/metrics"
 	"pvs/internal/repository"
 )
 
 type ReceptionService struct {
-	repo repository.ReceptionRepository
+	repo        repository.ReceptionRepository
+	pvzRepo     repository.PVZRepository
+	productRepo repository.ProductRepository
+	staff       repository.StaffRepository
+	tx          repository.Transactor
 }
 
-func NewReceptionService(repo repository.ReceptionRepository) *ReceptionService {
-	return &ReceptionService{repo: repo}
+func NewReceptionService(
+	repo repository.ReceptionRepository,
+	pvzRepo repository.PVZRepository,
+	productRepo repository.ProductRepository,
+	staff repository.StaffRepository,
+	tx repository.Transactor,
+) *ReceptionService {
+	return &ReceptionService{repo: repo, pvzRepo: pvzRepo, productRepo: productRepo, staff: staff, tx: tx}
 }
 
-func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error) {
-	if role != "employee" {
-		return nil, errors.New("доступ разрешён только сотрудникам ПВЗ")
+func (s *ReceptionService) CreateReception(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	if err := authorize(principal.Role, authz.OpenReception, "доступ разрешён только сотрудникам ПВЗ"); err != nil {
+		return nil, err
 	}
-	open, err := s.repo.GetOpenReception(ctx, pvzID)
-	if err == nil && open != nil {
-		return nil, errors.New("уже есть незакрытая приёмка")
+
+	var reception *domain.Reception
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := lockPVZ(ctx, s.pvzRepo, pvzID); err != nil {
+			return err
+		}
+		if err := requireStaff(ctx, s.staff, pvzID, principal); err != nil {
+			return err
+		}
+
+		_, err := s.repo.GetOpenReception(ctx, pvzID)
+		if err == nil {
+			return ErrReceptionAlreadyOpen
+		}
+		if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+
+		reception, err = s.repo.CreateReception(ctx, pvzID, principal.UserID)
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return ErrReceptionAlreadyOpen
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ReceptionsCreatedTotal.Inc()
+	return reception, nil
+}
+
+func (s *ReceptionService) CloseLastReception(
+	ctx context.Context,
+	pvzID uuid.UUID,
+	principal domain.Principal,
+	idempotencyKey string,
+) (*domain.Reception, error) {
+	if err := authorize(principal.Role, authz.CloseReception, "доступ разрешён только сотрудникам ПВЗ"); err != nil {
+		return nil, err
+	}
+
+	var (
+		reception *domain.Reception
+		replayed  bool
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := lockPVZ(ctx, s.pvzRepo, pvzID); err != nil {
+			return err
+		}
+		if err := requireStaff(ctx, s.staff, pvzID, principal); err != nil {
+			return err
+		}
+
+		if idempotencyKey != "" {
+			closed, err := s.repo.GetClosedByIdempotencyKey(ctx, pvzID, principal.UserID, idempotencyKey)
+			if err == nil {
+				reception, replayed = closed, true
+				return nil
+			}
+			if !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+		}
+
+		var err error
+		reception, err = s.repo.CloseLastReception(ctx, pvzID, principal.UserID, idempotencyKey)
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNoOpenReception
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !replayed {
+		metrics.ReceptionsClosedTotal.Inc()
+	}
+	return reception, nil
+}
+
+// ForceCloseReception closes a specific open reception regardless of who
+// opened it. It is meant for operators fixing a reception left open by
+// mistake, so it skips the staff assignment check.
+func (s *ReceptionService) ForceCloseReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.Reception, error) {
+	if err := authorize(principal.Role, authz.ForceCloseReception, "принудительно закрыть приёмку может только модератор"); err != nil {
+		return nil, err
+	}
+
+	reception, err := s.repo.GetReception(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errorWithMessage(ErrNotFound, "приёмка не найдена")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := lockPVZ(ctx, s.pvzRepo, reception.PVZID); err != nil {
+			return err
+		}
+
+		open, err := s.repo.GetOpenReception(ctx, reception.PVZID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && open.ID != id) {
+			return errorWithMessage(ErrNoOpenReception, "приёмка уже закрыта")
+		}
+		if err != nil {
+			return err
+		}
+
+		reception, err = s.repo.CloseLastReception(ctx, reception.PVZID, principal.UserID, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.ReceptionsClosedTotal.Inc()
+	return reception, nil
+}
+
+func (s *ReceptionService) ListReceptions(
+	ctx context.Context,
+	pvzID uuid.UUID,
+	filter domain.ReceptionFilter,
+	principal domain.Principal,
+) (*domain.ReceptionPage, error) {
+	if err := authorize(principal.Role, authz.ViewReceptions, "нет доступа к истории приёмок"); err != nil {
+		return nil, err
+	}
+	switch filter.Status {
+	case "", domain.ReceptionInProgress, domain.ReceptionClosed:
+	default:
+		return nil, errorWithMessage(ErrInvalidInput, "неизвестный статус приёмки: "+filter.Status)
+	}
+	if filter.Limit < 1 || filter.Limit > MaxPageLimit {
+		return nil, errorWithMessage(ErrInvalidInput, fmt.Sprintf("limit должен быть от 1 до %d", MaxPageLimit))
+	}
+	if filter.Page < 1 {
+		return nil, errorWithMessage(ErrInvalidInput, "page должен быть не меньше 1")
+	}
+
+	if _, err := s.pvzRepo.GetPVZ(ctx, pvzID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, errorWithMessage(ErrNotFound, "ПВЗ не найден")
+		}
+		return nil, err
+	}
+	if err := s.requireViewer(ctx, pvzID, principal); err != nil {
+		return nil, err
+	}
+
+	receptions, err := s.repo.ListReceptions(ctx, pvzID, filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.repo.CountReceptions(ctx, pvzID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ReceptionPage{Items: receptions, Total: total}
+	if len(receptions) > filter.Limit {
+		page.Items, page.HasMore = receptions[:filter.Limit], true
+	}
+	if page.Items == nil {
+		page.Items = []domain.Reception{}
+	}
+	return page, nil
+}
+
+func (s *ReceptionService) GetReception(ctx context.Context, id uuid.UUID, principal domain.Principal) (*domain.ReceptionWithProducts, error) {
+	if err := authorize(principal.Role, authz.ViewReceptions, "нет доступа к истории приёмок"); err != nil {
+		return nil, err
+	}
+
+	reception, err := s.repo.GetReception(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errorWithMessage(ErrNotFound, "приёмка не найдена")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireViewer(ctx, reception.PVZID, principal); err != nil {
+		return nil, err
+	}
+
+	products, err := s.productRepo.GetProductsByReception(ctx, reception.ID)
+	if err != nil {
+		return nil, err
+	}
+	if products == nil {
+		products = []domain.Product{}
+	}
+	return &domain.ReceptionWithProducts{Reception: *reception, Products: products}, nil
+}
+
+func (s *ReceptionService) requireViewer(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) error {
+	if authz.Can(principal.Role, authz.ViewAnyReceptions) {
+		return nil
 	}
-	return s.repo.CreateReception(ctx, pvzID)
+	return requireStaff(ctx, s.staff, pvzID, principal)
 }
 
-func (s *ReceptionService) CloseLastReception(ctx context.Context, pvzID uuid.UUID, role string) (*domain.Reception, error) {
-	if role != "employee" {
-		return nil, errors.New("доступ разрешён только сотрудникам ПВЗ")
+func lockPVZ(ctx context.Context, pvzRepo repository.PVZRepository, pvzID uuid.UUID) error {
+	err := pvzRepo.LockPVZ(ctx, pvzID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errorWithMessage(ErrNotFound, "ПВЗ не найден")
 	}
-	return s.repo.CloseLastReception(ctx, pvzID)
+	return err
 }
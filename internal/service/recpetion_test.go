@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"pvs/internal/domain"
+	"pvs/internal/repository"
 	"pvs/internal/service"
 )
 
@@ -24,78 +26,422 @@ func (m *mockReceptionRepo) GetOpenReception(ctx context.Context, pvzID uuid.UUI
 	return nil, args.Error(1)
 }
 
-func (m *mockReceptionRepo) CreateReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error) {
-	args := m.Called(ctx, pvzID)
-	return args.Get(0).(*domain.Reception), args.Error(1)
+func (m *mockReceptionRepo) CreateReception(ctx context.Context, pvzID, createdBy uuid.UUID) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, createdBy)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func (m *mockReceptionRepo) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*domain.Reception, error) {
-	args := m.Called(ctx, pvzID)
-	return args.Get(0).(*domain.Reception), args.Error(1)
+func (m *mockReceptionRepo) CloseLastReception(ctx context.Context, pvzID, closedBy uuid.UUID, idempotencyKey string) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, closedBy, idempotencyKey)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
-func TestCreateReception_Success(t *testing.T) {
+func (m *mockReceptionRepo) GetClosedByIdempotencyKey(ctx context.Context, pvzID, closedBy uuid.UUID, idempotencyKey string) (*domain.Reception, error) {
+	args := m.Called(ctx, pvzID, closedBy, idempotencyKey)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionRepo) GetReception(ctx context.Context, id uuid.UUID) (*domain.Reception, error) {
+	args := m.Called(ctx, id)
+	if rec := args.Get(0); rec != nil {
+		return rec.(*domain.Reception), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockReceptionRepo) ListReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter) ([]domain.Reception, error) {
+	args := m.Called(ctx, pvzID, filter)
+	return args.Get(0).([]domain.Reception), args.Error(1)
+}
+
+func (m *mockReceptionRepo) CountReceptions(ctx context.Context, pvzID uuid.UUID, filter domain.ReceptionFilter) (int, error) {
+	args := m.Called(ctx, pvzID, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockReceptionRepo) ListByPVZIDs(ctx context.Context, pvzIDs []uuid.UUID, startDate, endDate *time.Time) ([]domain.Reception, error) {
+	args := m.Called(ctx, pvzIDs, startDate, endDate)
+	return args.Get(0).([]domain.Reception), args.Error(1)
+}
+
+var (
+	employee  = domain.Principal{UserID: uuid.MustParse("8a7d1c3e-2b4f-4e6a-9c1d-0f2e3a4b5c6d"), Role: "employee"}
+	moderator = domain.Principal{UserID: uuid.MustParse("1b2c3d4e-5f60-4a7b-8c9d-0e1f2a3b4c5d"), Role: "moderator"}
+)
+
+type fakeTransactor struct {
+	calls int
+}
+
+func (f *fakeTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.calls++
+	return fn(ctx)
+}
+
+func newReceptionService() (*service.ReceptionService, *mockReceptionRepo, *mockPVZRepo, *fakeTransactor) {
 	repo := new(mockReceptionRepo)
-	svc := service.NewReceptionService(repo)
+	pvzRepo := new(mockPVZRepo)
+	tx := new(fakeTransactor)
+	return service.NewReceptionService(repo, pvzRepo, nil, assignedStaff(), tx), repo, pvzRepo, tx
+}
+
+func TestCreateReception_Success(t *testing.T) {
+	svc, repo, pvzRepo, tx := newReceptionService()
 
 	pvzID := uuid.New()
 	expected := &domain.Reception{ID: uuid.New(), PVZID: pvzID}
 
-	repo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, errors.New("not found"))
-	repo.On("CreateReception", mock.Anything, pvzID).Return(expected, nil)
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, repository.ErrNotFound)
+	repo.On("CreateReception", mock.Anything, pvzID, employee.UserID).Return(expected, nil)
 
-	rec, err := svc.CreateReception(context.Background(), pvzID, "employee")
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
 	assert.NoError(t, err)
 	assert.Equal(t, expected, rec)
+	assert.Equal(t, 1, tx.calls)
 	repo.AssertExpectations(t)
+	pvzRepo.AssertExpectations(t)
 }
 
 func TestCreateReception_AlreadyOpen(t *testing.T) {
-	repo := new(mockReceptionRepo)
-	svc := service.NewReceptionService(repo)
+	svc, repo, pvzRepo, _ := newReceptionService()
 
 	pvzID := uuid.New()
 	existing := &domain.Reception{ID: uuid.New(), PVZID: pvzID}
 
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
 	repo.On("GetOpenReception", mock.Anything, pvzID).Return(existing, nil)
 
-	rec, err := svc.CreateReception(context.Background(), pvzID, "employee")
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
 	assert.Nil(t, rec)
 	assert.EqualError(t, err, "уже есть незакрытая приёмка")
+	assert.ErrorIs(t, err, service.ErrReceptionAlreadyOpen)
 	repo.AssertExpectations(t)
 }
 
+func TestCreateReception_LookupError(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	dbErr := errors.New("db down")
+
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, dbErr)
+
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, dbErr)
+	repo.AssertNotCalled(t, "CreateReception", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestCreateReception_NotEmployee(t *testing.T) {
-	repo := new(mockReceptionRepo)
-	svc := service.NewReceptionService(repo)
+	svc, _, _, _ := newReceptionService()
 
 	pvzID := uuid.New()
-	rec, err := svc.CreateReception(context.Background(), pvzID, "moderator")
+	rec, err := svc.CreateReception(context.Background(), pvzID, moderator)
 	assert.Nil(t, rec)
 	assert.EqualError(t, err, "доступ разрешён только сотрудникам ПВЗ")
 }
 
 func TestCloseLastReception_Success(t *testing.T) {
-	repo := new(mockReceptionRepo)
-	svc := service.NewReceptionService(repo)
+	svc, repo, pvzRepo, tx := newReceptionService()
 
 	pvzID := uuid.New()
 	expected := &domain.Reception{ID: uuid.New(), PVZID: pvzID}
 
-	repo.On("CloseLastReception", mock.Anything, pvzID).Return(expected, nil)
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("CloseLastReception", mock.Anything, pvzID, employee.UserID, "").Return(expected, nil)
 
-	rec, err := svc.CloseLastReception(context.Background(), pvzID, "employee")
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, employee, "")
 	assert.NoError(t, err)
 	assert.Equal(t, expected, rec)
+	assert.Equal(t, 1, tx.calls)
 	repo.AssertExpectations(t)
 }
 
 func TestCloseLastReception_NotEmployee(t *testing.T) {
-	repo := new(mockReceptionRepo)
-	svc := service.NewReceptionService(repo)
+	svc, _, _, _ := newReceptionService()
 
 	pvzID := uuid.New()
-	rec, err := svc.CloseLastReception(context.Background(), pvzID, "moderator")
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, moderator, "")
 	assert.Nil(t, rec)
 	assert.EqualError(t, err, "доступ разрешён только сотрудникам ПВЗ")
 }
+
+func TestCreateReception_PVZNotFound(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(repository.ErrNotFound)
+
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	repo.AssertNotCalled(t, "CreateReception", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateReception_ConcurrentInsertRejected(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetOpenReception", mock.Anything, pvzID).Return(nil, repository.ErrNotFound)
+	repo.On("CreateReception", mock.Anything, pvzID, employee.UserID).Return(nil, repository.ErrAlreadyExists)
+
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrReceptionAlreadyOpen)
+}
+
+func TestCloseLastReception_NoOpenReception(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("CloseLastReception", mock.Anything, pvzID, employee.UserID, "").Return(nil, repository.ErrNotFound)
+
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, employee, "")
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrNoOpenReception)
+}
+
+func TestCloseLastReception_IdempotentReplay(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	closed := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: "close"}
+
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetClosedByIdempotencyKey", mock.Anything, pvzID, employee.UserID, "key-1").Return(closed, nil)
+
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, employee, "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, closed, rec)
+	repo.AssertNotCalled(t, "CloseLastReception", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCloseLastReception_FirstCallWithKey(t *testing.T) {
+	svc, repo, pvzRepo, _ := newReceptionService()
+
+	pvzID := uuid.New()
+	closed := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: "close"}
+
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetClosedByIdempotencyKey", mock.Anything, pvzID, employee.UserID, "key-1").Return(nil, repository.ErrNotFound)
+	repo.On("CloseLastReception", mock.Anything, pvzID, employee.UserID, "key-1").Return(closed, nil)
+
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, employee, "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, closed, rec)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateReception_NotAssignedToPVZ(t *testing.T) {
+	repo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	staffRepo := new(mockStaffRepo)
+	svc := service.NewReceptionService(repo, pvzRepo, nil, staffRepo, new(fakeTransactor))
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(false, nil)
+
+	rec, err := svc.CreateReception(context.Background(), pvzID, employee)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	assert.EqualError(t, err, "сотрудник не назначен на этот ПВЗ")
+	repo.AssertNotCalled(t, "CreateReception", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCloseLastReception_NotAssignedToPVZ(t *testing.T) {
+	repo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	staffRepo := new(mockStaffRepo)
+	svc := service.NewReceptionService(repo, pvzRepo, nil, staffRepo, new(fakeTransactor))
+
+	pvzID := uuid.New()
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(false, nil)
+
+	rec, err := svc.CloseLastReception(context.Background(), pvzID, employee, "key-1")
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "GetClosedByIdempotencyKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func newReceptionHistoryService() (*service.ReceptionService, *mockReceptionRepo, *mockPVZRepo, *mockProductRepo, *mockStaffRepo) {
+	repo := new(mockReceptionRepo)
+	pvzRepo := new(mockPVZRepo)
+	productRepo := new(mockProductRepo)
+	staffRepo := new(mockStaffRepo)
+	return service.NewReceptionService(repo, pvzRepo, productRepo, staffRepo, new(fakeTransactor)), repo, pvzRepo, productRepo, staffRepo
+}
+
+func TestListReceptions_ModeratorSeesAnyPVZ(t *testing.T) {
+	svc, repo, pvzRepo, _, staffRepo := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	first := domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: domain.ReceptionClosed}
+	second := domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: domain.ReceptionClosed}
+	pvzRepo.On("GetPVZ", mock.Anything, pvzID).Return(&domain.PVZ{ID: pvzID}, nil)
+	repo.On("ListReceptions", mock.Anything, pvzID, domain.ReceptionFilter{Status: "close", Page: 1, Limit: 2}).
+		Return([]domain.Reception{first, second, {ID: uuid.New()}}, nil)
+	repo.On("CountReceptions", mock.Anything, pvzID, mock.Anything).Return(5, nil)
+
+	page, err := svc.ListReceptions(context.Background(), pvzID, domain.ReceptionFilter{Status: "close", Page: 1, Limit: 2}, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Reception{first, second}, page.Items)
+	assert.Equal(t, 5, page.Total)
+	assert.True(t, page.HasMore)
+	staffRepo.AssertNotCalled(t, "IsAssigned", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListReceptions_EmployeeMustBeAssigned(t *testing.T) {
+	svc, repo, pvzRepo, _, staffRepo := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("GetPVZ", mock.Anything, pvzID).Return(&domain.PVZ{ID: pvzID}, nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(false, nil)
+
+	page, err := svc.ListReceptions(context.Background(), pvzID, domain.ReceptionFilter{Page: 1, Limit: 10}, employee)
+	assert.Nil(t, page)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "ListReceptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListReceptions_EmptyIsNotNil(t *testing.T) {
+	svc, repo, pvzRepo, _, staffRepo := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("GetPVZ", mock.Anything, pvzID).Return(&domain.PVZ{ID: pvzID}, nil)
+	staffRepo.On("IsAssigned", mock.Anything, pvzID, employee.UserID).Return(true, nil)
+	repo.On("ListReceptions", mock.Anything, pvzID, mock.Anything).Return([]domain.Reception(nil), nil)
+	repo.On("CountReceptions", mock.Anything, pvzID, mock.Anything).Return(0, nil)
+
+	page, err := svc.ListReceptions(context.Background(), pvzID, domain.ReceptionFilter{Page: 1, Limit: 10}, employee)
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Items)
+	assert.False(t, page.HasMore)
+}
+
+func TestListReceptions_InvalidFilter(t *testing.T) {
+	svc, _, pvzRepo, _, _ := newReceptionHistoryService()
+
+	for _, filter := range []domain.ReceptionFilter{
+		{Status: "open", Page: 1, Limit: 10},
+		{Page: 1, Limit: 0},
+		{Page: 1, Limit: service.MaxPageLimit + 1},
+		{Page: 0, Limit: 10},
+	} {
+		_, err := svc.ListReceptions(context.Background(), uuid.New(), filter, moderator)
+		assert.ErrorIs(t, err, service.ErrInvalidInput)
+	}
+	pvzRepo.AssertNotCalled(t, "GetPVZ", mock.Anything, mock.Anything)
+}
+
+func TestListReceptions_PVZNotFound(t *testing.T) {
+	svc, _, pvzRepo, _, _ := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	pvzRepo.On("GetPVZ", mock.Anything, pvzID).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.ListReceptions(context.Background(), pvzID, domain.ReceptionFilter{Page: 1, Limit: 10}, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestGetReception_WithProducts(t *testing.T) {
+	svc, repo, _, productRepo, staffRepo := newReceptionHistoryService()
+
+	rec := &domain.Reception{ID: uuid.New(), PVZID: uuid.New(), Status: domain.ReceptionClosed}
+	products := []domain.Product{{ID: uuid.New(), Type: "обувь", ReceptionID: rec.ID}}
+	repo.On("GetReception", mock.Anything, rec.ID).Return(rec, nil)
+	staffRepo.On("IsAssigned", mock.Anything, rec.PVZID, employee.UserID).Return(true, nil)
+	productRepo.On("GetProductsByReception", mock.Anything, rec.ID).Return(products, nil)
+
+	result, err := svc.GetReception(context.Background(), rec.ID, employee)
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.ReceptionWithProducts{Reception: *rec, Products: products}, result)
+}
+
+func TestGetReception_NotFound(t *testing.T) {
+	svc, repo, _, productRepo, _ := newReceptionHistoryService()
+
+	id := uuid.New()
+	repo.On("GetReception", mock.Anything, id).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.GetReception(context.Background(), id, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	productRepo.AssertNotCalled(t, "GetProductsByReception", mock.Anything, mock.Anything)
+}
+
+func TestGetReception_OtherPVZForbidden(t *testing.T) {
+	svc, repo, _, productRepo, staffRepo := newReceptionHistoryService()
+
+	rec := &domain.Reception{ID: uuid.New(), PVZID: uuid.New()}
+	repo.On("GetReception", mock.Anything, rec.ID).Return(rec, nil)
+	staffRepo.On("IsAssigned", mock.Anything, rec.PVZID, employee.UserID).Return(false, nil)
+
+	_, err := svc.GetReception(context.Background(), rec.ID, employee)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	productRepo.AssertNotCalled(t, "GetProductsByReception", mock.Anything, mock.Anything)
+}
+
+func TestForceCloseReception_Success(t *testing.T) {
+	svc, repo, pvzRepo, _, staffRepo := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	open := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: domain.ReceptionInProgress}
+	closed := &domain.Reception{ID: open.ID, PVZID: pvzID, Status: domain.ReceptionClosed}
+	repo.On("GetReception", mock.Anything, open.ID).Return(open, nil)
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetOpenReception", mock.Anything, pvzID).Return(open, nil)
+	repo.On("CloseLastReception", mock.Anything, pvzID, moderator.UserID, "").Return(closed, nil)
+
+	rec, err := svc.ForceCloseReception(context.Background(), open.ID, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, closed, rec)
+	staffRepo.AssertNotCalled(t, "IsAssigned", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForceCloseReception_AlreadyClosed(t *testing.T) {
+	svc, repo, pvzRepo, _, _ := newReceptionHistoryService()
+
+	pvzID := uuid.New()
+	stale := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: domain.ReceptionClosed}
+	newer := &domain.Reception{ID: uuid.New(), PVZID: pvzID, Status: domain.ReceptionInProgress}
+	repo.On("GetReception", mock.Anything, stale.ID).Return(stale, nil)
+	pvzRepo.On("LockPVZ", mock.Anything, pvzID).Return(nil)
+	repo.On("GetOpenReception", mock.Anything, pvzID).Return(newer, nil)
+
+	rec, err := svc.ForceCloseReception(context.Background(), stale.ID, moderator)
+	assert.Nil(t, rec)
+	assert.ErrorIs(t, err, service.ErrNoOpenReception)
+	repo.AssertNotCalled(t, "CloseLastReception", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForceCloseReception_NotFound(t *testing.T) {
+	svc, repo, _, _, _ := newReceptionHistoryService()
+
+	id := uuid.New()
+	repo.On("GetReception", mock.Anything, id).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.ForceCloseReception(context.Background(), id, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestForceCloseReception_OnlyModerator(t *testing.T) {
+	svc, repo, _, _, _ := newReceptionHistoryService()
+
+	_, err := svc.ForceCloseReception(context.Background(), uuid.New(), employee)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	repo.AssertNotCalled(t, "GetReception", mock.Anything, mock.Anything)
+}
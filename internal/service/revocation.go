@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pvs/internal/repository"
+)
+
+type RevocationList struct {
+	repo repository.RevokedTokenRepository
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	checked map[string]time.Time
+}
+
+func NewRevocationList(repo repository.RevokedTokenRepository, ttl time.Duration) *RevocationList {
+	return &RevocationList{
+		repo:    repo,
+		ttl:     ttl,
+		revoked: make(map[string]time.Time),
+		checked: make(map[string]time.Time),
+	}
+}
+
+func (l *RevocationList) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if err := l.repo.RevokeToken(ctx, jti, expiresAt); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.revoked[jti] = expiresAt
+	delete(l.checked, jti)
+	return nil
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	if _, ok := l.revoked[jti]; ok {
+		l.mu.Unlock()
+		return true, nil
+	}
+	if until, ok := l.checked[jti]; ok && now.Before(until) {
+		l.mu.Unlock()
+		return false, nil
+	}
+	l.mu.Unlock()
+
+	revoked, err := l.repo.IsRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if revoked {
+		l.revoked[jti] = now.Add(l.ttl)
+		delete(l.checked, jti)
+	} else {
+		l.checked[jti] = now.Add(l.ttl)
+	}
+	return revoked, nil
+}
+
+func (l *RevocationList) Prune(ctx context.Context) error {
+	now := time.Now()
+
+	l.mu.Lock()
+	for jti, expiresAt := range l.revoked {
+		if now.After(expiresAt) {
+			delete(l.revoked, jti)
+		}
+	}
+	for jti, until := range l.checked {
+		if now.After(until) {
+			delete(l.checked, jti)
+		}
+	}
+	l.mu.Unlock()
+
+	return l.repo.DeleteExpiredRevocations(ctx)
+}
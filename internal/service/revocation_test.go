@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/service"
+)
+
+func TestRevocationList_CachesNegativeLookups(t *testing.T) {
+	store := &memoryRevocations{revoked: make(map[string]time.Time)}
+	list := service.NewRevocationList(store, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		revoked, err := list.IsRevoked(context.Background(), "jti-1")
+		require.NoError(t, err)
+		assert.False(t, revoked)
+	}
+	assert.Equal(t, 1, store.lookups)
+}
+
+func TestRevocationList_RevokeOverridesCachedAnswer(t *testing.T) {
+	store := &memoryRevocations{revoked: make(map[string]time.Time)}
+	list := service.NewRevocationList(store, time.Hour)
+
+	revoked, err := list.IsRevoked(context.Background(), "jti-1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, list.Revoke(context.Background(), "jti-1", time.Now().Add(time.Hour)))
+
+	revoked, err = list.IsRevoked(context.Background(), "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.Contains(t, store.revoked, "jti-1")
+}
+
+func TestRevocationList_SeesRevocationsFromOtherInstances(t *testing.T) {
+	store := &memoryRevocations{revoked: make(map[string]time.Time)}
+	list := service.NewRevocationList(store, time.Millisecond)
+
+	revoked, _ := list.IsRevoked(context.Background(), "jti-1")
+	assert.False(t, revoked)
+
+	store.revoked["jti-1"] = time.Now().Add(time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	revoked, err := list.IsRevoked(context.Background(), "jti-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
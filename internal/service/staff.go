@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+type StaffService struct {
+	repo  repository.StaffRepository
+	users repository.UserRepository
+}
+
+func NewStaffService(repo repository.StaffRepository, users repository.UserRepository) *StaffService {
+	return &StaffService{repo: repo, users: users}
+}
+
+func (s *StaffService) AssignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) (*domain.StaffAssignment, error) {
+	if err := authorize(principal.Role, authz.ManageStaff, "только модератор может назначать сотрудников"); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmployee(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	assignment, err := s.repo.AssignStaff(ctx, pvzID, userID, principal.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errorWithMessage(ErrNotFound, "ПВЗ не найден")
+	}
+	return assignment, err
+}
+
+func (s *StaffService) UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID, principal domain.Principal) error {
+	if err := authorize(principal.Role, authz.ManageStaff, "только модератор может назначать сотрудников"); err != nil {
+		return err
+	}
+
+	err := s.repo.UnassignStaff(ctx, pvzID, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errorWithMessage(ErrNotFound, "сотрудник не назначен на этот ПВЗ")
+	}
+	return err
+}
+
+func (s *StaffService) ListStaff(ctx context.Context, pvzID uuid.UUID, principal domain.Principal) ([]domain.StaffAssignment, error) {
+	if err := authorize(principal.Role, authz.ManageStaff, "только модератор может просматривать сотрудников ПВЗ"); err != nil {
+		return nil, err
+	}
+
+	staff, err := s.repo.ListStaff(ctx, pvzID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		staff = []domain.StaffAssignment{}
+	}
+	return staff, nil
+}
+
+func (s *StaffService) checkEmployee(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.users.GetByID(ctx, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return errorWithMessage(ErrNotFound, "пользователь не найден")
+	}
+	if err != nil {
+		return err
+	}
+	if !authz.Can(user.Role, authz.OpenReception) {
+		return errorWithMessage(ErrInvalidInput, "назначить на ПВЗ можно только сотрудника")
+	}
+	return nil
+}
+
+func requireStaff(ctx context.Context, staff repository.StaffRepository, pvzID uuid.UUID, principal domain.Principal) error {
+	assigned, err := staff.IsAssigned(ctx, pvzID, principal.UserID)
+	if err != nil {
+		return err
+	}
+	if !assigned {
+		return errorWithMessage(ErrForbidden, "сотрудник не назначен на этот ПВЗ")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+	"pvs/internal/service"
+)
+
+type mockStaffRepo struct {
+	mock.Mock
+}
+
+func (m *mockStaffRepo) AssignStaff(ctx context.Context, pvzID, userID, assignedBy uuid.UUID) (*domain.StaffAssignment, error) {
+	args := m.Called(ctx, pvzID, userID, assignedBy)
+	if a := args.Get(0); a != nil {
+		return a.(*domain.StaffAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockStaffRepo) UnassignStaff(ctx context.Context, pvzID, userID uuid.UUID) error {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Error(0)
+}
+
+func (m *mockStaffRepo) ListStaff(ctx context.Context, pvzID uuid.UUID) ([]domain.StaffAssignment, error) {
+	args := m.Called(ctx, pvzID)
+	return args.Get(0).([]domain.StaffAssignment), args.Error(1)
+}
+
+func (m *mockStaffRepo) IsAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, pvzID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func assignedStaff() *mockStaffRepo {
+	repo := new(mockStaffRepo)
+	repo.On("IsAssigned", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	return repo
+}
+
+func TestAssignStaff_Success(t *testing.T) {
+	repo := new(mockStaffRepo)
+	users := new(mockUserRepo)
+	svc := service.NewStaffService(repo, users)
+
+	pvzID, userID := uuid.New(), uuid.New()
+	expected := &domain.StaffAssignment{PVZID: pvzID, UserID: userID}
+	users.On("GetByID", mock.Anything, userID).Return(&domain.User{ID: userID, Role: "employee"}, nil)
+	repo.On("AssignStaff", mock.Anything, pvzID, userID, moderator.UserID).Return(expected, nil)
+
+	assignment, err := svc.AssignStaff(context.Background(), pvzID, userID, moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, assignment)
+	repo.AssertExpectations(t)
+}
+
+func TestAssignStaff_OnlyModerator(t *testing.T) {
+	svc := service.NewStaffService(nil, nil)
+
+	_, err := svc.AssignStaff(context.Background(), uuid.New(), employee.UserID, employee)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+}
+
+func TestAssignStaff_RejectsNonEmployee(t *testing.T) {
+	repo := new(mockStaffRepo)
+	users := new(mockUserRepo)
+	svc := service.NewStaffService(repo, users)
+
+	userID := uuid.New()
+	users.On("GetByID", mock.Anything, userID).Return(&domain.User{ID: userID, Role: "moderator"}, nil)
+
+	_, err := svc.AssignStaff(context.Background(), uuid.New(), userID, moderator)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+	repo.AssertNotCalled(t, "AssignStaff", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAssignStaff_UnknownUser(t *testing.T) {
+	users := new(mockUserRepo)
+	svc := service.NewStaffService(new(mockStaffRepo), users)
+
+	userID := uuid.New()
+	users.On("GetByID", mock.Anything, userID).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.AssignStaff(context.Background(), uuid.New(), userID, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.EqualError(t, err, "пользователь не найден")
+}
+
+func TestAssignStaff_PVZNotFound(t *testing.T) {
+	repo := new(mockStaffRepo)
+	users := new(mockUserRepo)
+	svc := service.NewStaffService(repo, users)
+
+	pvzID, userID := uuid.New(), uuid.New()
+	users.On("GetByID", mock.Anything, userID).Return(&domain.User{ID: userID, Role: "employee"}, nil)
+	repo.On("AssignStaff", mock.Anything, pvzID, userID, moderator.UserID).Return(nil, repository.ErrNotFound)
+
+	_, err := svc.AssignStaff(context.Background(), pvzID, userID, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.EqualError(t, err, "ПВЗ не найден")
+}
+
+func TestUnassignStaff_NotAssigned(t *testing.T) {
+	repo := new(mockStaffRepo)
+	svc := service.NewStaffService(repo, nil)
+
+	pvzID, userID := uuid.New(), uuid.New()
+	repo.On("UnassignStaff", mock.Anything, pvzID, userID).Return(repository.ErrNotFound)
+
+	err := svc.UnassignStaff(context.Background(), pvzID, userID, moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestListStaff_EmptyIsNotNil(t *testing.T) {
+	repo := new(mockStaffRepo)
+	svc := service.NewStaffService(repo, nil)
+
+	pvzID := uuid.New()
+	repo.On("ListStaff", mock.Anything, pvzID).Return([]domain.StaffAssignment(nil), nil)
+
+	staff, err := svc.ListStaff(context.Background(), pvzID, moderator)
+	assert.NoError(t, err)
+	assert.NotNil(t, staff)
+	assert.Empty(t, staff)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"pvs/internal/domain"
+)
+
+type TokenIssuer struct {
+	secret   []byte
+	keyID    string
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// NewTokenIssuer signs tokens with secret and stamps keyID as their kid so
+// verifiers can pick the right key while secrets are rotated. An empty keyID
+// leaves kid unset.
+func NewTokenIssuer(secret []byte, keyID, issuer, audience string, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{secret: secret, keyID: keyID, issuer: issuer, audience: audience, ttl: ttl}
+}
+
+func (t *TokenIssuer) TTL() time.Duration {
+	return t.ttl
+}
+
+func (t *TokenIssuer) Issue(user domain.User) (string, error) {
+	return t.issue(user, false)
+}
+
+func (t *TokenIssuer) IssueDummy(user domain.User) (string, error) {
+	return t.issue(user, true)
+}
+
+func (t *TokenIssuer) issue(user domain.User, dummy bool) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":       user.ID.String(),
+		"email":     user.Email,
+		"user_type": user.Role,
+		"iss":       t.issuer,
+		"aud":       t.audience,
+		"iat":       now.Unix(),
+		"exp":       now.Add(t.ttl).Unix(),
+		"jti":       uuid.NewString(),
+	}
+	if dummy {
+		claims["dummy"] = true
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if t.keyID != "" {
+		token.Header["kid"] = t.keyID
+	}
+	return token.SignedString(t.secret)
+}
+
+var dummyUserNamespace = uuid.MustParse("6f1c8a52-4c1e-4f7a-9d0b-3b7d2f1e9a60")
+
+func DummyUser(role string) domain.User {
+	return domain.User{
+		ID:    uuid.NewSHA1(dummyUserNamespace, []byte(role)),
+		Email: role + "@dummy.local",
+		Role:  role,
+	}
+}
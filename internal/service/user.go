@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+type UserService struct {
+	repo      repository.UserRepository
+	passwords PasswordPolicy
+}
+
+func NewUserService(repo repository.UserRepository, passwords PasswordPolicy) *UserService {
+	return &UserService{repo: repo, passwords: passwords}
+}
+
+func (s *UserService) CreateUser(ctx context.Context, email, password, role string, principal domain.Principal) (*domain.User, error) {
+	if err := authorize(principal.Role, authz.ManageUsers, "управлять пользователями может только модератор"); err != nil {
+		return nil, err
+	}
+	if !authz.ValidRole(role) {
+		return nil, errorWithMessage(ErrInvalidInput, "неизвестная роль: "+role)
+	}
+	return createUser(ctx, s.repo, s.passwords, email, password, role)
+}
+
+func (s *UserService) ListUsers(ctx context.Context, principal domain.Principal) ([]domain.User, error) {
+	if err := authorize(principal.Role, authz.ManageUsers, "управлять пользователями может только модератор"); err != nil {
+		return nil, err
+	}
+	users, err := s.repo.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []domain.User{}
+	}
+	return users, nil
+}
+
+// SetRole changes the role of an existing user. Access tokens already issued
+// keep the old role until they expire; the next refresh picks up the new one.
+func (s *UserService) SetRole(ctx context.Context, id uuid.UUID, role string, principal domain.Principal) (*domain.User, error) {
+	if err := authorize(principal.Role, authz.ManageUsers, "управлять пользователями может только модератор"); err != nil {
+		return nil, err
+	}
+	if !authz.ValidRole(role) {
+		return nil, errorWithMessage(ErrInvalidInput, "неизвестная роль: "+role)
+	}
+
+	user, err := s.repo.SetRole(ctx, id, role)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, errorWithMessage(ErrNotFound, "пользователь не найден")
+	}
+	return user, err
+}
+
+func createUser(
+	ctx context.Context,
+	repo repository.UserRepository,
+	passwords PasswordPolicy,
+	email, password, role string,
+) (*domain.User, error) {
+	email = NormalizeEmail(email)
+	if email == "" || password == "" {
+		return nil, errorWithMessage(ErrInvalidInput, "email/password required")
+	}
+	if err := validateEmail(email); err != nil {
+		return nil, err
+	}
+	if err := passwords.Validate(password); err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{
+		Email:    email,
+		Password: string(hash),
+		Role:     role,
+	}
+	if err := repo.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, err
+	}
+	return user, nil
+}
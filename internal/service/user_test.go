@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+	"pvs/internal/service"
+)
+
+func TestCreateUser_Success(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+	repo.On("CreateUser", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil)
+
+	user, err := svc.CreateUser(context.Background(), " Mod@Example.com ", "password1", "moderator", moderator)
+	require.NoError(t, err)
+	assert.Equal(t, "mod@example.com", user.Email)
+	assert.Equal(t, "moderator", user.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("password1")))
+}
+
+func TestCreateUser_Validation(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+
+	_, err := svc.CreateUser(context.Background(), "a@example.com", "password1", "admin", moderator)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+
+	_, err = svc.CreateUser(context.Background(), "a@example.com", "short", "employee", moderator)
+	assert.ErrorIs(t, err, service.ErrWeakPassword)
+
+	_, err = svc.CreateUser(context.Background(), "a@example.com", "password1", "employee", employee)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	repo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestCreateUser_Duplicate(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+	repo.On("CreateUser", mock.Anything, mock.Anything).Return(repository.ErrAlreadyExists)
+
+	_, err := svc.CreateUser(context.Background(), "a@example.com", "password1", "employee", moderator)
+	assert.ErrorIs(t, err, service.ErrUserAlreadyExists)
+}
+
+func TestListUsers_EmptyIsNotNil(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+	repo.On("ListUsers", mock.Anything).Return([]domain.User(nil), nil)
+
+	users, err := svc.ListUsers(context.Background(), moderator)
+	assert.NoError(t, err)
+	assert.NotNil(t, users)
+}
+
+func TestSetRole(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+
+	id := uuid.New()
+	updated := &domain.User{ID: id, Email: "a@example.com", Role: "moderator"}
+	repo.On("SetRole", mock.Anything, id, "moderator").Return(updated, nil)
+
+	user, err := svc.SetRole(context.Background(), id, "moderator", moderator)
+	assert.NoError(t, err)
+	assert.Equal(t, updated, user)
+}
+
+func TestSetRole_Errors(t *testing.T) {
+	repo := new(mockUserRepo)
+	svc := service.NewUserService(repo, passwordPolicy)
+
+	missing := uuid.New()
+	repo.On("SetRole", mock.Anything, missing, "employee").Return(nil, repository.ErrNotFound)
+
+	_, err := svc.SetRole(context.Background(), missing, "employee", moderator)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, err = svc.SetRole(context.Background(), uuid.New(), "root", moderator)
+	assert.ErrorIs(t, err, service.ErrInvalidInput)
+}
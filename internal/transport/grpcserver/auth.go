@@ -0,0 +1,53 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/pb/pvz_v1"
+	"pvs/internal/transport/middleware"
+)
+
+// Authenticator validates the value of the "authorization" metadata key.
+type Authenticator interface {
+	Authenticate(ctx context.Context, authorization string) (domain.Principal, error)
+}
+
+// methodActions lists the action each RPC requires. Methods missing from it
+// are refused so a new RPC cannot be exposed without a permission.
+var methodActions = map[string]authz.Action{
+	pvz_v1.PVZService_GetPVZList_FullMethodName: authz.ListPVZ,
+}
+
+// authInterceptor is the gRPC counterpart of AuthMiddleware plus Require.
+func authInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var authorization string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				authorization = values[0]
+			}
+		}
+
+		principal, err := auth.Authenticate(ctx, authorization)
+		if errors.Is(err, middleware.ErrRevocationCheck) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		action, ok := methodActions[info.FullMethod]
+		if !ok || authz.Check(principal, action) != nil {
+			return nil, status.Error(codes.PermissionDenied, "недостаточно прав для этого действия")
+		}
+		return handler(middleware.WithPrincipal(ctx, principal), req)
+	}
+}
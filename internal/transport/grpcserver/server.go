@@ -0,0 +1,83 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pvs/internal/domain"
+	"pvs/internal/pb/pvz_v1"
+	"pvs/internal/service"
+)
+
+// defaultPageLimit matches the HTTP GET /pvz default.
+const defaultPageLimit = 10
+
+type PVZServiceInterface interface {
+	ListPVZPage(ctx context.Context, filter domain.PVZFilter) (*domain.Page[domain.PVZ], error)
+}
+
+type PVZServer struct {
+	pvz_v1.UnimplementedPVZServiceServer
+	service PVZServiceInterface
+}
+
+func NewPVZServer(service PVZServiceInterface) *PVZServer {
+	return &PVZServer{service: service}
+}
+
+func NewServer(service PVZServiceInterface, auth Authenticator) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(auth)))
+	pvz_v1.RegisterPVZServiceServer(server, NewPVZServer(service))
+	return server
+}
+
+func (s *PVZServer) GetPVZList(ctx context.Context, req *pvz_v1.GetPVZListRequest) (*pvz_v1.GetPVZListResponse, error) {
+	filter := domain.PVZFilter{Page: int(req.GetPage()), Limit: int(req.GetLimit())}
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+	if cursor := req.GetCursor(); cursor != "" {
+		after, err := domain.DecodePVZCursor(cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "неверный cursor")
+		}
+		filter.After = after
+	}
+
+	page, err := s.service.ListPVZPage(ctx, filter)
+	if errors.Is(err, service.ErrInvalidInput) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		log.Printf("❌ grpc GetPVZList error: %v", err)
+		return nil, status.Error(codes.Internal, "ошибка получения списка ПВЗ")
+	}
+
+	resp := &pvz_v1.GetPVZListResponse{
+		Pvzs:       make([]*pvz_v1.PVZ, 0, len(page.Items)),
+		Total:      int32(page.Total),
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}
+	for _, p := range page.Items {
+		resp.Pvzs = append(resp.Pvzs, ToProtoPVZ(p))
+	}
+	return resp, nil
+}
+
+func ToProtoPVZ(p domain.PVZ) *pvz_v1.PVZ {
+	return &pvz_v1.PVZ{
+		Id:               p.ID.String(),
+		RegistrationDate: timestamppb.New(p.RegistrationDate),
+		City:             p.City,
+	}
+}
@@ -0,0 +1,176 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/pb/pvz_v1"
+	svc "pvs/internal/service"
+	"pvs/internal/transport/grpcserver"
+	"pvs/internal/transport/middleware"
+)
+
+type mockPVZService struct {
+	mock.Mock
+}
+
+func (m *mockPVZService) ListPVZPage(ctx context.Context, filter domain.PVZFilter) (*domain.Page[domain.PVZ], error) {
+	args := m.Called(ctx, filter)
+	if p := args.Get(0); p != nil {
+		return p.(*domain.Page[domain.PVZ]), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+const testSecret = "grpc-secret"
+
+// withToken attaches a bearer token for role to the outgoing call.
+func withToken(role string) context.Context {
+	now := time.Now()
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":       uuid.NewString(),
+		"user_type": role,
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+		"jti":       uuid.NewString(),
+	}).SignedString([]byte(testSecret))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func newClient(t *testing.T, service grpcserver.PVZServiceInterface) pvz_v1.PVZServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	keys := middleware.NewHMACKeySet([]byte(testSecret))
+	server := grpcserver.NewServer(service, middleware.NewAuthenticator(middleware.AuthConfig{Keys: keys}))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pvz_v1.NewPVZServiceClient(conn)
+}
+
+func TestGetPVZList_Success(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	registered := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	pvz := domain.PVZ{ID: uuid.New(), City: "Казань", RegistrationDate: registered}
+	service.On("ListPVZPage", mock.Anything, domain.PVZFilter{Page: 1, Limit: 10}).
+		Return(&domain.Page[domain.PVZ]{Items: []domain.PVZ{pvz}, Total: 1}, nil)
+
+	resp, err := client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Pvzs, 1)
+	assert.Equal(t, pvz.ID.String(), resp.Pvzs[0].Id)
+	assert.Equal(t, "Казань", resp.Pvzs[0].City)
+	assert.True(t, registered.Equal(resp.Pvzs[0].RegistrationDate.AsTime()))
+	assert.Equal(t, int32(1), resp.Total)
+	assert.False(t, resp.HasMore)
+	service.AssertExpectations(t)
+}
+
+func TestGetPVZList_Paging(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	service.On("ListPVZPage", mock.Anything, domain.PVZFilter{Page: 3, Limit: 2}).
+		Return(&domain.Page[domain.PVZ]{
+			Items:      []domain.PVZ{{ID: uuid.New()}, {ID: uuid.New()}},
+			Total:      9,
+			HasMore:    true,
+			NextCursor: "next",
+		}, nil)
+
+	resp, err := client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{Page: 3, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, resp.Pvzs, 2)
+	assert.Equal(t, int32(9), resp.Total)
+	assert.True(t, resp.HasMore)
+	assert.Equal(t, "next", resp.NextCursor)
+	service.AssertExpectations(t)
+}
+
+func TestGetPVZList_Cursor(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	cursor := domain.PVZCursor{RegistrationDate: time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC), ID: uuid.New()}
+	service.On("ListPVZPage", mock.Anything, mock.MatchedBy(func(f domain.PVZFilter) bool {
+		return f.Limit == 5 && f.After != nil && f.After.ID == cursor.ID && f.After.RegistrationDate.Equal(cursor.RegistrationDate)
+	})).Return(&domain.Page[domain.PVZ]{}, nil)
+
+	_, err := client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{Limit: 5, Cursor: cursor.Encode()})
+	require.NoError(t, err)
+	service.AssertExpectations(t)
+}
+
+func TestGetPVZList_InvalidArgument(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	_, err := client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{Cursor: "%%%"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	service.AssertNotCalled(t, "ListPVZPage")
+
+	service.On("ListPVZPage", mock.Anything, domain.PVZFilter{Page: 1, Limit: 1000}).
+		Return(nil, svc.ErrInvalidInput)
+
+	_, err = client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{Limit: 1000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetPVZList_ServiceError(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	service.On("ListPVZPage", mock.Anything, mock.Anything).Return(nil, errors.New("db down"))
+
+	_, err := client.GetPVZList(withToken(authz.RoleEmployee), &pvz_v1.GetPVZListRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestGetPVZList_RequiresToken(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	_, err := client.GetPVZList(context.Background(), &pvz_v1.GetPVZListRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-jwt")
+	_, err = client.GetPVZList(ctx, &pvz_v1.GetPVZListRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	service.AssertNotCalled(t, "ListPVZPage")
+}
+
+func TestGetPVZList_RequiresListPermission(t *testing.T) {
+	service := new(mockPVZService)
+	client := newClient(t, service)
+
+	_, err := client.GetPVZList(withToken("guest"), &pvz_v1.GetPVZListRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	service.AssertNotCalled(t, "ListPVZPage")
+}
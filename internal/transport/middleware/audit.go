@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+)
+
+func AuditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		p, _ := PrincipalFromContext(r.Context())
+		log.Printf("📝 audit: user=%s email=%s role=%s token=%s dummy=%t %s %s -> %d",
+			p.UserID, p.Email, p.Role, p.TokenID, p.Dummy, r.Method, r.URL.Path, rec.status)
+	})
+}
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestAuditMiddleware_LogsMutations(t *testing.T) {
+	buf := captureLog(t)
+	userID := uuid.New()
+
+	handler := middleware.AuditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/receptions", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), domain.Principal{
+		UserID: userID,
+		Email:  "employee@pvz.ru",
+		Role:   "employee",
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, buf.String(), "user="+userID.String())
+	assert.Contains(t, buf.String(), "email=employee@pvz.ru")
+	assert.Contains(t, buf.String(), "dummy=false")
+	assert.Contains(t, buf.String(), "POST /receptions -> 201")
+}
+
+func TestAuditMiddleware_SkipsReads(t *testing.T) {
+	buf := captureLog(t)
+
+	handler := middleware.AuditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/pvz", nil))
+
+	assert.Empty(t, buf.String())
+}
@@ -2,53 +2,152 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"pvs/internal/domain"
 )
 
-type contextKey string
+type principalCtxKey struct{}
 
-const (
-	UserTypeKey contextKey = "user_type"
-)
-
-var secret = []byte("super-secret")
+func WithPrincipal(ctx context.Context, p domain.Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, p)
+}
 
-type RoleCtxKey struct{}
+func PrincipalFromContext(ctx context.Context) (domain.Principal, bool) {
+	p, ok := ctx.Value(principalCtxKey{}).(domain.Principal)
+	return p, ok
+}
 
 func GetUserRole(ctx context.Context) string {
-	role, _ := ctx.Value(RoleCtxKey{}).(string)
-	return role
+	p, _ := PrincipalFromContext(ctx)
+	return p.Role
 }
 
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		auth := r.Header.Get("Authorization")
-		if !strings.HasPrefix(auth, "Bearer ") {
-			http.Error(w, "missing token", http.StatusUnauthorized)
-			return
+var validMethods = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodES256.Alg(),
+}
+
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type AuthConfig struct {
+	Keys        KeyProvider
+	Issuer      string
+	Audience    string
+	Revocations RevocationChecker
+}
+
+var (
+	ErrMissingToken    = errors.New("missing token")
+	ErrInvalidToken    = errors.New("invalid token")
+	ErrInvalidClaims   = errors.New("invalid claims")
+	ErrTokenRevoked    = errors.New("token revoked")
+	ErrRevocationCheck = errors.New("revocation check failed")
+)
+
+// Authenticator validates access tokens. The HTTP middleware and the gRPC
+// interceptor share it so both transports accept exactly the same tokens.
+type Authenticator struct {
+	cfg    AuthConfig
+	parser *jwt.Parser
+}
+
+func NewAuthenticator(cfg AuthConfig) *Authenticator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return &Authenticator{cfg: cfg, parser: jwt.NewParser(opts...)}
+}
+
+// Authenticate validates an Authorization header value of the form
+// "Bearer <token>" and returns the principal it carries.
+func (a *Authenticator) Authenticate(ctx context.Context, authorization string) (domain.Principal, error) {
+	tokenStr, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return domain.Principal{}, ErrMissingToken
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := a.parser.ParseWithClaims(tokenStr, claims, a.cfg.Keys.Key)
+	if err != nil || !token.Valid {
+		return domain.Principal{}, ErrInvalidToken
+	}
+
+	principal, err := principalFromClaims(claims)
+	if err != nil {
+		return domain.Principal{}, ErrInvalidClaims
+	}
+
+	if a.cfg.Revocations != nil {
+		revoked, err := a.cfg.Revocations.IsRevoked(ctx, principal.TokenID)
+		if err != nil {
+			log.Printf("❌ revocation check failed: %v", err)
+			return domain.Principal{}, ErrRevocationCheck
 		}
+		if revoked {
+			return domain.Principal{}, ErrTokenRevoked
+		}
+	}
+	return principal, nil
+}
 
-		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return secret, nil
+func NewAuthMiddleware(cfg AuthConfig) func(http.Handler) http.Handler {
+	auth := NewAuthenticator(cfg)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := auth.Authenticate(r.Context(), r.Header.Get("Authorization"))
+			if errors.Is(err, ErrRevocationCheck) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
 		})
-		if err != nil || !token.Valid {
-			http.Error(w, "invalid token", http.StatusUnauthorized)
-			return
-		}
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "invalid claims", http.StatusUnauthorized)
-			return
-		}
+var errMissingClaim = errors.New("required claim is missing")
+
+func principalFromClaims(claims jwt.MapClaims) (domain.Principal, error) {
+	var p domain.Principal
+	p.Role, _ = claims["user_type"].(string)
+	p.Email, _ = claims["email"].(string)
+	p.TokenID, _ = claims["jti"].(string)
+	p.Dummy, _ = claims["dummy"].(bool)
+	if p.Role == "" || p.TokenID == "" {
+		return domain.Principal{}, errMissingClaim
+	}
 
-		userType, _ := claims["user_type"].(string)
-		ctx := context.WithValue(r.Context(), UserTypeKey, userType)
+	sub, _ := claims["sub"].(string)
+	id, err := uuid.Parse(sub)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	p.UserID = id
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return domain.Principal{}, errMissingClaim
+	}
+	p.ExpiresAt = exp.Time
+	return p, nil
 }
@@ -1,27 +1,57 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"pvs/internal/domain"
 	"pvs/internal/transport/middleware"
 )
 
-func generateToken(role string) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+const (
+	testIssuer   = "pvz-test"
+	testAudience = "pvz-test-api"
+)
+
+func validClaims(role string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":       uuid.NewString(),
 		"user_type": role,
-		"exp":       time.Now().Add(time.Hour).Unix(),
-	})
+		"iss":       testIssuer,
+		"aud":       testAudience,
+		"iat":       now.Unix(),
+		"exp":       now.Add(time.Hour).Unix(),
+		"jti":       uuid.NewString(),
+	}
+}
+
+func signClaims(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenStr, _ := token.SignedString([]byte("super-secret"))
 	return tokenStr
 }
 
+func generateToken(role string) string {
+	return signClaims(validClaims(role))
+}
+
+func newAuth() func(http.Handler) http.Handler {
+	return middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:     middleware.NewHMACKeySet([]byte("super-secret")),
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})
+}
+
 func TestAuthMiddleware_MissingToken(t *testing.T) {
-	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -35,7 +65,7 @@ func TestAuthMiddleware_MissingToken(t *testing.T) {
 }
 
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
-	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -53,9 +83,11 @@ func TestAuthMiddleware_Success(t *testing.T) {
 	role := "moderator"
 	token := generateToken(role)
 
-	handler := middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		val := r.Context().Value(middleware.UserTypeKey)
-		assert.Equal(t, role, val)
+	handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, role, principal.Role)
+		assert.Equal(t, role, middleware.GetUserRole(r.Context()))
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -66,3 +98,110 @@ func TestAuthMiddleware_Success(t *testing.T) {
 	handler.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestAuthMiddleware_PrincipalFromClaims(t *testing.T) {
+	userID := uuid.New()
+	claims := validClaims("employee")
+	claims["sub"] = userID.String()
+	claims["email"] = "employee@pvz.ru"
+	claims["jti"] = "token-1"
+	expiresAt := time.Unix(claims["exp"].(int64), 0)
+	tokenStr := signClaims(claims)
+
+	handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := middleware.PrincipalFromContext(r.Context())
+		assert.True(t, ok)
+		assert.Equal(t, domain.Principal{
+			UserID:    userID,
+			Email:     "employee@pvz.ru",
+			Role:      "employee",
+			TokenID:   "token-1",
+			ExpiresAt: expiresAt,
+		}, principal)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenStr)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_InvalidSubject(t *testing.T) {
+	claims := validClaims("employee")
+	claims["sub"] = "not-a-uuid"
+	tokenStr := signClaims(claims)
+
+	handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenStr)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_RejectsInvalidStandardClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(jwt.MapClaims)
+	}{
+		{"wrong issuer", func(c jwt.MapClaims) { c["iss"] = "someone-else" }},
+		{"wrong audience", func(c jwt.MapClaims) { c["aud"] = "other-api" }},
+		{"missing exp", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"issued in the future", func(c jwt.MapClaims) { c["iat"] = time.Now().Add(time.Hour).Unix() }},
+		{"missing jti", func(c jwt.MapClaims) { delete(c, "jti") }},
+		{"missing sub", func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims("employee")
+			tt.mutate(claims)
+
+			handler := newAuth()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signClaims(claims))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
+type staticRevocations map[string]bool
+
+func (s staticRevocations) IsRevoked(_ context.Context, jti string) (bool, error) {
+	return s[jti], nil
+}
+
+func TestAuthMiddleware_RejectsRevokedToken(t *testing.T) {
+	claims := validClaims("employee")
+	claims["jti"] = "revoked-jti"
+
+	handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:        middleware.NewHMACKeySet([]byte("super-secret")),
+		Issuer:      testIssuer,
+		Audience:    testAudience,
+		Revocations: staticRevocations{"revoked-jti": true},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signClaims(claims))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "token revoked")
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"pvs/internal/authz"
+)
+
+type forbiddenResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Require answers 403 unless the authenticated principal may perform action.
+func Require(action authz.Action) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, _ := PrincipalFromContext(r.Context())
+			if err := authz.Check(principal, action); err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(forbiddenResponse{
+					Code:    "forbidden",
+					Message: "недостаточно прав для этого действия",
+				})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
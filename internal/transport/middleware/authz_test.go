@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/authz"
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+func TestRequire(t *testing.T) {
+	called := false
+	handler := middleware.Require(authz.CreatePVZ)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), domain.Principal{Role: authz.RoleEmployee}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"code":"forbidden","message":"недостаточно прав для этого действия"}`, w.Body.String())
+	assert.False(t, called)
+
+	req = httptest.NewRequest(http.MethodPost, "/pvz", nil)
+	req = req.WithContext(middleware.WithPrincipal(req.Context(), domain.Principal{Role: authz.RoleModerator}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.True(t, called)
+}
+
+func TestRequire_NoPrincipal(t *testing.T) {
+	handler := middleware.Require(authz.ListPVZ)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not be called")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pvz", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
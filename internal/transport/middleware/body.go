@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type tooLargeResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// LimitBody rejects requests whose declared Content-Length exceeds limit and
+// caps the body of the rest, so a chunked upload cannot exceed it either.
+func LimitBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+				json.NewEncoder(w).Encode(tooLargeResponse{
+					Code:    "payload_too_large",
+					Message: "тело запроса слишком большое",
+				})
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
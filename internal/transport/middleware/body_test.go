@@ -0,0 +1,41 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/transport/middleware"
+)
+
+func TestLimitBody_RejectsDeclaredLength(t *testing.T) {
+	called := false
+	handler := middleware.LimitBody(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz", strings.NewReader("too long"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "payload_too_large")
+	assert.False(t, called)
+}
+
+func TestLimitBody_CapsUnknownLength(t *testing.T) {
+	var readErr error
+	handler := middleware.LimitBody(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz", strings.NewReader("too long"))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var tooLarge *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &tooLarge)
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"pvs/internal/domain"
+	"pvs/internal/repository"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+type idempotencyError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewIdempotencyMiddleware(store repository.IdempotencyRepository, ttl time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > MaxIdempotencyKeyLength {
+				writeIdempotencyError(w, http.StatusBadRequest, "bad_request", "слишком длинный Idempotency-Key")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentRequestBytes+1))
+			var tooLarge *http.MaxBytesError
+			if len(body) > maxIdempotentRequestBytes || errors.As(err, &tooLarge) {
+				writeIdempotencyError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "тело запроса слишком большое")
+				return
+			}
+			if err != nil {
+				writeIdempotencyError(w, http.StatusBadRequest, "bad_request", "не удалось прочитать тело запроса")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			principal, _ := PrincipalFromContext(r.Context())
+			rec := &domain.IdempotencyRecord{
+				Key:         key,
+				Principal:   principalKey(principal),
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: requestHash(r.Method, r.URL.Path, body),
+				ExpiresAt:   time.Now().Add(ttl),
+			}
+
+			storeCtx := context.WithoutCancel(r.Context())
+			existing, reserved, err := store.Reserve(storeCtx, rec)
+			if err != nil {
+				log.Printf("❌ idempotency reserve: %v", err)
+				writeIdempotencyError(w, http.StatusInternalServerError, "internal_error", "внутренняя ошибка сервера")
+				return
+			}
+
+			if !reserved {
+				replay(w, rec, existing)
+				return
+			}
+
+			release := func() {
+				if err := store.Release(storeCtx, rec.Key, rec.Principal); err != nil {
+					log.Printf("❌ idempotency release: %v", err)
+				}
+			}
+			// a panicking handler must not leave the key reserved until it
+			// expires, otherwise every retry is answered with 409
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+
+			capture := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(capture, r)
+
+			if capture.status >= http.StatusInternalServerError {
+				release()
+				return
+			}
+
+			contentType := capture.Header().Get("Content-Type")
+			if err := store.Complete(storeCtx, rec.Key, rec.Principal, capture.status, contentType, capture.body.Bytes()); err != nil {
+				log.Printf("❌ idempotency complete: %v", err)
+			}
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, rec, existing *domain.IdempotencyRecord) {
+	if existing.RequestHash != rec.RequestHash {
+		writeIdempotencyError(w, http.StatusUnprocessableEntity, "idempotency_key_reused",
+			"Idempotency-Key уже использован для другого запроса")
+		return
+	}
+	if !existing.Completed() {
+		writeIdempotencyError(w, http.StatusConflict, "idempotency_request_in_progress",
+			"запрос с этим Idempotency-Key ещё выполняется")
+		return
+	}
+
+	if existing.ContentType != "" {
+		w.Header().Set("Content-Type", existing.ContentType)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(existing.StatusCode)
+	w.Write(existing.ResponseBody)
+}
+
+func principalKey(p domain.Principal) string {
+	if p.UserID != uuid.Nil {
+		return p.UserID.String()
+	}
+	return "role:" + p.Role
+}
+
+func requestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(idempotencyError{Code: code, Message: message})
+}
+
+type responseCapture struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	c.status = status
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
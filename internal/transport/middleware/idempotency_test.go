@@ -0,0 +1,208 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/domain"
+	"pvs/internal/transport/middleware"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{records: make(map[string]*domain.IdempotencyRecord)}
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, rec *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := rec.Key + "|" + rec.Principal
+	if existing, ok := s.records[id]; ok && existing.ExpiresAt.After(time.Now()) {
+		copied := *existing
+		return &copied, false, nil
+	}
+	copied := *rec
+	s.records[id] = &copied
+	return rec, true, nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key, principal string, status int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := s.records[key+"|"+principal]
+	rec.StatusCode = status
+	rec.ContentType = contentType
+	rec.ResponseBody = append([]byte(nil), body...)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key, principal string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key+"|"+principal)
+	return nil
+}
+
+func (s *memoryIdempotencyStore) DeleteExpired(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, rec := range s.records {
+		if rec.ExpiresAt.Before(time.Now()) {
+			delete(s.records, id)
+		}
+	}
+	return nil
+}
+
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	fmt.Fprintf(w, `{"call":%d}`, h.calls)
+}
+
+func idempotentRequest(key string, principal domain.Principal, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/products", bytes.NewReader([]byte(body)))
+	if key != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+	}
+	return req.WithContext(middleware.WithPrincipal(req.Context(), principal))
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, idempotentRequest("key-1", principal, `{"type":"обувь"}`))
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, idempotentRequest("key-1", principal, `{"type":"обувь"}`))
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+func TestIdempotency_WithoutHeaderPassesThrough(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+	principal := domain.Principal{Role: "employee"}
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("", principal, `{}`))
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("", principal, `{}`))
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", principal, `{"type":"обувь"}`))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("key-1", principal, `{"type":"одежда"}`))
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "idempotency_key_reused")
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	var handler http.Handler
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retry := httptest.NewRecorder()
+		handler.ServeHTTP(retry, idempotentRequest("key-1", principal, `{}`))
+		assert.Equal(t, http.StatusConflict, retry.Code)
+		assert.Contains(t, retry.Body.String(), "idempotency_request_in_progress")
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler = middleware.NewIdempotencyMiddleware(store, time.Hour)(inner)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("key-1", principal, `{}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotency_ServerErrorIsNotStored(t *testing.T) {
+	next := &countingHandler{status: http.StatusInternalServerError}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", principal, `{}`))
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", principal, `{}`))
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestIdempotency_ScopedPerPrincipal(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", domain.Principal{UserID: uuid.New()}, `{}`))
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", domain.Principal{UserID: uuid.New()}, `{}`))
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestIdempotency_OversizedBodyRejected(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(newMemoryIdempotencyStore(), time.Hour)(next)
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("key-1", principal, strings.Repeat("a", 1<<20+1)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "payload_too_large")
+	assert.Zero(t, next.calls)
+}
+
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	panicking := middleware.NewIdempotencyMiddleware(store, time.Hour)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	principal := domain.Principal{UserID: uuid.New(), Role: "employee"}
+
+	assert.PanicsWithValue(t, "boom", func() {
+		panicking.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", principal, `{}`))
+	})
+
+	next := &countingHandler{status: http.StatusCreated}
+	handler := middleware.NewIdempotencyMiddleware(store, time.Hour)(next)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, idempotentRequest("key-1", principal, `{}`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, next.calls)
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKeyID    = errors.New("unknown key id")
+	ErrSigningMismatch = errors.New("signing method does not match key")
+)
+
+type KeyProvider interface {
+	Key(token *jwt.Token) (interface{}, error)
+}
+
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]verificationKey
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]verificationKey)}
+}
+
+func NewHMACKeySet(secret []byte) *KeySet {
+	ks := NewKeySet()
+	ks.AddHMAC("", secret)
+	return ks
+}
+
+func (ks *KeySet) AddHMAC(kid string, secret []byte) {
+	ks.set(kid, verificationKey{alg: jwt.SigningMethodHS256.Alg(), key: secret})
+}
+
+func (ks *KeySet) AddPublicKey(kid string, key interface{}) error {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		ks.set(kid, verificationKey{alg: jwt.SigningMethodRS256.Alg(), key: k})
+	case *ecdsa.PublicKey:
+		ks.set(kid, verificationKey{alg: jwt.SigningMethodES256.Alg(), key: k})
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+	return nil
+}
+
+func (ks *KeySet) AddPublicKeyFile(kid, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read public key %s: %w", path, err)
+	}
+
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return ks.AddPublicKey(kid, rsaKey)
+	}
+	ecKey, err := jwt.ParseECPublicKeyFromPEM(data)
+	if err != nil {
+		return fmt.Errorf("parse public key %s: %w", path, err)
+	}
+	return ks.AddPublicKey(kid, ecKey)
+}
+
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	delete(ks.keys, kid)
+}
+
+func (ks *KeySet) Key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	ks.mu.RLock()
+	vk, ok := ks.keys[kid]
+	ks.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	if token.Method.Alg() != vk.alg {
+		return nil, ErrSigningMismatch
+	}
+	return vk.key, nil
+}
+
+func (ks *KeySet) set(kid string, vk verificationKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys[kid] = vk
+}
@@ -0,0 +1,122 @@
+package middleware_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pvs/internal/transport/middleware"
+)
+
+func writePublicKey(t *testing.T, pub interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	token := jwt.NewWithClaims(method, validClaims("employee"))
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenStr, err := token.SignedString(key)
+	require.NoError(t, err)
+	return tokenStr
+}
+
+func serve(keys middleware.KeyProvider, token string) int {
+	handler := middleware.NewAuthMiddleware(middleware.AuthConfig{
+		Keys:     keys,
+		Issuer:   testIssuer,
+		Audience: testAudience,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestKeySet_RS256FromFile(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := middleware.NewKeySet()
+	require.NoError(t, keys.AddPublicKeyFile("rsa-1", writePublicKey(t, &priv.PublicKey)))
+
+	assert.Equal(t, http.StatusOK, serve(keys, signToken(t, jwt.SigningMethodRS256, "rsa-1", priv)))
+}
+
+func TestKeySet_ES256FromFile(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys := middleware.NewKeySet()
+	require.NoError(t, keys.AddPublicKeyFile("ec-1", writePublicKey(t, &priv.PublicKey)))
+
+	assert.Equal(t, http.StatusOK, serve(keys, signToken(t, jwt.SigningMethodES256, "ec-1", priv)))
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	keys := middleware.NewKeySet()
+	keys.AddHMAC("old", []byte("old-secret"))
+	keys.AddHMAC("new", []byte("new-secret"))
+
+	oldToken := signToken(t, jwt.SigningMethodHS256, "old", []byte("old-secret"))
+	newToken := signToken(t, jwt.SigningMethodHS256, "new", []byte("new-secret"))
+
+	assert.Equal(t, http.StatusOK, serve(keys, oldToken))
+	assert.Equal(t, http.StatusOK, serve(keys, newToken))
+
+	keys.Remove("old")
+	assert.Equal(t, http.StatusUnauthorized, serve(keys, oldToken))
+	assert.Equal(t, http.StatusOK, serve(keys, newToken))
+}
+
+func TestKeySet_UnknownKeyID(t *testing.T) {
+	keys := middleware.NewHMACKeySet([]byte("secret"))
+
+	token := signToken(t, jwt.SigningMethodHS256, "missing", []byte("secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve(keys, token))
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	path := writePublicKey(t, &priv.PublicKey)
+
+	keys := middleware.NewKeySet()
+	require.NoError(t, keys.AddPublicKeyFile("rsa-1", path))
+
+	pemBytes, err := os.ReadFile(path)
+	require.NoError(t, err)
+	forged := signToken(t, jwt.SigningMethodHS256, "rsa-1", pemBytes)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(keys, forged))
+}
+
+func TestKeySet_InvalidKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+
+	keys := middleware.NewKeySet()
+	assert.Error(t, keys.AddPublicKeyFile("broken", path))
+}
@@ -0,0 +1,43 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"pvs/internal/metrics"
+	"pvs/internal/transport/middleware"
+)
+
+func TestMetricsMiddleware_RecordsRouteAndStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /pvz/{pvzId}/close_last_reception", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	})
+	handler := middleware.MetricsMiddleware(mux)
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodPost, "POST /pvz/{pvzId}/close_last_reception", "409")
+	before := testutil.ToFloat64(counter)
+
+	req := httptest.NewRequest(http.MethodPost, "/pvz/123/close_last_reception", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
+
+func TestMetricsMiddleware_UnmatchedRoute(t *testing.T) {
+	handler := middleware.MetricsMiddleware(http.NewServeMux())
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "unmatched", "404")
+	before := testutil.ToFloat64(counter)
+
+	req := httptest.NewRequest(http.MethodGet, "/unknown/path", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(counter))
+}
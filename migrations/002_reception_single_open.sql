@@ -0,0 +1,20 @@
+-- +goose Up
+-- earlier versions could leave several receptions open for one PVZ;
+-- keep the newest one open and close the rest so the index can be built
+UPDATE reception r
+SET status = 'close'
+WHERE r.status = 'in_progress'
+  AND EXISTS (
+      SELECT 1
+      FROM reception newer
+      WHERE newer.pvz_id = r.pvz_id
+        AND newer.status = 'in_progress'
+        AND (newer.date_time, newer.id) > (r.date_time, r.id)
+  );
+
+CREATE UNIQUE INDEX reception_one_in_progress_per_pvz
+    ON reception (pvz_id)
+    WHERE status = 'in_progress';
+
+-- +goose Down
+DROP INDEX IF EXISTS reception_one_in_progress_per_pvz;
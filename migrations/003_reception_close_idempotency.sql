@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE reception ADD COLUMN close_idempotency_key TEXT;
+
+CREATE UNIQUE INDEX reception_close_idempotency_key
+    ON reception (pvz_id, close_idempotency_key)
+    WHERE close_idempotency_key IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS reception_close_idempotency_key;
+ALTER TABLE reception DROP COLUMN IF EXISTS close_idempotency_key;
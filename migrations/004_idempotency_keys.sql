@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE idempotency_key (
+                                 key TEXT NOT NULL,
+                                 principal TEXT NOT NULL,
+                                 method TEXT NOT NULL,
+                                 path TEXT NOT NULL,
+                                 request_hash TEXT NOT NULL,
+                                 status_code INT,
+                                 content_type TEXT NOT NULL DEFAULT '',
+                                 response_body BYTEA,
+                                 created_at TIMESTAMP NOT NULL DEFAULT now(),
+                                 expires_at TIMESTAMP NOT NULL,
+                                 PRIMARY KEY (key, principal)
+);
+
+CREATE INDEX idempotency_key_expires_at ON idempotency_key (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS idempotency_key;
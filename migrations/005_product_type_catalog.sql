@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE product_type (
+                              name TEXT PRIMARY KEY,
+                              created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO product_type (name) VALUES ('электроника'), ('одежда'), ('обувь');
+
+ALTER TABLE product DROP CONSTRAINT IF EXISTS product_type_check;
+ALTER TABLE product
+    ADD CONSTRAINT product_type_fkey FOREIGN KEY (type) REFERENCES product_type (name) ON UPDATE CASCADE;
+
+-- +goose Down
+ALTER TABLE product DROP CONSTRAINT IF EXISTS product_type_fkey;
+ALTER TABLE product
+    ADD CONSTRAINT product_type_check CHECK (type IN ('электроника', 'одежда', 'обувь'));
+DROP TABLE IF EXISTS product_type;
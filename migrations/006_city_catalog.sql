@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE city (
+                      name TEXT PRIMARY KEY,
+                      enabled BOOLEAN NOT NULL DEFAULT true,
+                      created_at TIMESTAMP NOT NULL DEFAULT now(),
+                      updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO city (name) VALUES ('Москва'), ('Санкт-Петербург'), ('Казань');
+
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS pvz_city_check;
+ALTER TABLE pvz
+    ADD CONSTRAINT pvz_city_fkey FOREIGN KEY (city) REFERENCES city (name) ON UPDATE CASCADE;
+
+-- +goose Down
+ALTER TABLE pvz DROP CONSTRAINT IF EXISTS pvz_city_fkey;
+ALTER TABLE pvz
+    ADD CONSTRAINT pvz_city_check CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань'));
+DROP TABLE IF EXISTS city;
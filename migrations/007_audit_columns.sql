@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE reception
+    ADD COLUMN created_by UUID,
+    ADD COLUMN closed_by UUID;
+
+ALTER TABLE product
+    ADD COLUMN created_by UUID;
+
+-- +goose Down
+ALTER TABLE product DROP COLUMN IF EXISTS created_by;
+ALTER TABLE reception
+    DROP COLUMN IF EXISTS closed_by,
+    DROP COLUMN IF EXISTS created_by;
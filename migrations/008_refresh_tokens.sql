@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE refresh_token (
+                               id UUID PRIMARY KEY,
+                               user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               family_id UUID NOT NULL,
+                               token_hash TEXT NOT NULL UNIQUE,
+                               expires_at TIMESTAMP NOT NULL,
+                               created_at TIMESTAMP NOT NULL DEFAULT now(),
+                               revoked_at TIMESTAMP,
+                               replaced_by UUID
+);
+
+CREATE INDEX refresh_token_family_idx ON refresh_token (family_id);
+
+CREATE TABLE revoked_token (
+                               jti TEXT PRIMARY KEY,
+                               expires_at TIMESTAMP NOT NULL,
+                               revoked_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS revoked_token;
+DROP TABLE IF EXISTS refresh_token;
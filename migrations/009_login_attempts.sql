@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE login_attempt (
+                               key TEXT PRIMARY KEY,
+                               failures INT NOT NULL DEFAULT 0,
+                               locked_until TIMESTAMP,
+                               updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+UPDATE users u
+SET email = lower(trim(u.email))
+WHERE u.email <> lower(trim(u.email))
+  AND NOT EXISTS (
+    SELECT 1 FROM users o
+    WHERE o.id <> u.id AND lower(trim(o.email)) = lower(trim(u.email))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempt;
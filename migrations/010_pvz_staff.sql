@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE pvz_staff (
+                           pvz_id UUID NOT NULL REFERENCES pvz(id) ON DELETE CASCADE,
+                           user_id UUID NOT NULL,
+                           assigned_by UUID,
+                           assigned_at TIMESTAMP NOT NULL DEFAULT now(),
+                           PRIMARY KEY (pvz_id, user_id)
+);
+
+CREATE INDEX pvz_staff_user_id_idx ON pvz_staff (user_id);
+
+INSERT INTO pvz_staff (pvz_id, user_id)
+SELECT DISTINCT pvz_id, created_by FROM reception
+WHERE created_by IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS pvz_staff;
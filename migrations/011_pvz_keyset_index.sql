@@ -0,0 +1,7 @@
+-- +goose Up
+CREATE INDEX pvz_registration_date_id_idx ON pvz (registration_date DESC, id DESC);
+CREATE INDEX reception_pvz_id_date_time_idx ON reception (pvz_id, date_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS reception_pvz_id_date_time_idx;
+DROP INDEX IF EXISTS pvz_registration_date_id_idx;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Assignments of users that do not exist (in dev, the /dummyLogin employee
+-- before it had a users row) cannot satisfy the new key. They are removed and
+-- the count is reported so the operator can re-assign them.
+-- +goose StatementBegin
+DO $$
+DECLARE
+    orphans INT;
+BEGIN
+    DELETE FROM pvz_staff s
+    WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.id = s.user_id);
+    GET DIAGNOSTICS orphans = ROW_COUNT;
+    IF orphans > 0 THEN
+        RAISE NOTICE 'pvz_staff: removed % assignment(s) of users that do not exist', orphans;
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+ALTER TABLE pvz_staff
+    ADD CONSTRAINT pvz_staff_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE pvz_staff DROP CONSTRAINT IF EXISTS pvz_staff_user_id_fkey;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Close keys are scoped per user like the Idempotency-Key middleware, so two
+-- employees of one PVZ may use the same key.
+DROP INDEX IF EXISTS reception_close_idempotency_key;
+CREATE UNIQUE INDEX reception_close_idempotency_key
+    ON reception (pvz_id, closed_by, close_idempotency_key)
+    WHERE close_idempotency_key IS NOT NULL;
+
+-- +goose Down
+-- Fails if two users of one PVZ already share a key.
+DROP INDEX IF EXISTS reception_close_idempotency_key;
+CREATE UNIQUE INDEX reception_close_idempotency_key
+    ON reception (pvz_id, close_idempotency_key)
+    WHERE close_idempotency_key IS NOT NULL;
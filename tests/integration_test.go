@@ -2,8 +2,10 @@ package tests
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -23,6 +25,22 @@ func postJSON(t *testing.T, url string, body any, token string) *http.Response {
 	return resp
 }
 
+func tokenSubject(t *testing.T, token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatal("malformed token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("decode token payload: %v", err)
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	_ = json.Unmarshal(payload, &claims)
+	return claims.Sub
+}
+
 func TestHappyPathReceptionFlow(t *testing.T) {
 	resp := postJSON(t, baseURL+"/dummyLogin", map[string]string{
 		"role": "employee",
@@ -62,6 +80,13 @@ func TestHappyPathReceptionFlow(t *testing.T) {
 
 	pvzID := pvz.ID
 
+	staffResp := postJSON(t, baseURL+"/pvz/"+pvzID+"/staff", map[string]string{
+		"userId": tokenSubject(t, token),
+	}, modToken)
+	if staffResp.StatusCode != http.StatusCreated {
+		t.Fatal("failed to assign employee to PVZ")
+	}
+
 	recResp := postJSON(t, baseURL+"/receptions", map[string]string{
 		"pvzId": pvzID,
 	}, token)